@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// dsnVariable is the environment variable that passes the connection string
+// to the generated program, so it is never written to disk.
+const dsnVariable = "GOUTIL_MIGRATION_DSN"
+
+// source is the exported variable of an application package holding its
+// registered migrations, given as "<import path>.<name>".
+type source struct {
+	Import string // Import path of the package
+	Name   string // Name of the []migrator.Migration variable
+}
+
+// parseSource reads a source given as "<import path>.<name>",
+// e.g. "example.com/app/migrations.All".
+func parseSource(text string) (source, error) {
+	dot := strings.LastIndex(text, ".")
+	if dot <= strings.LastIndex(text, "/") || dot == 0 {
+		return source{}, fmt.Errorf("migrations %q must be given as <import path>.<variable>", text)
+	}
+
+	s := source{Import: text[:dot], Name: text[dot+1:]}
+	if !token.IsIdentifier(s.Name) || !token.IsExported(s.Name) {
+		return source{}, fmt.Errorf("migrations %q must name an exported variable", text)
+	}
+	return s, nil
+}
+
+// program holds the values of the generated program.
+type program struct {
+	Source  source // Variable holding the registered migrations
+	Schema  string // Schema compared, the current schema when empty
+	Dir     string // Directory where the migration is written
+	Name    string // Name of the migration
+	Format  string // Kind of file to write
+	Package string // Package of a Go migration
+}
+
+// programTemplate is the source of the program that registers the migrations
+// of the application, compares them with the database and writes the result.
+var programTemplate = template.Must(template.New("program").Funcs(template.FuncMap{"quote": func(s string) string {
+	return fmt.Sprintf("%q", s)
+}}).Parse(`// Code generated by github.com/pinzlab/goutil/cmd/migration. DO NOT EDIT.
+
+package main
+
+import (
+	"errors"
+	"os"
+
+	source {{quote .Source.Import}}
+	"github.com/pinzlab/goutil/pg"
+	"github.com/pinzlab/goutil/pg/migrator"
+	"github.com/pinzlab/goutil/terminal"
+)
+
+func main() {
+	var opts []migrator.Option
+	if schema := {{quote .Schema}}; schema != "" {
+		opts = append(opts, migrator.WithSchema(schema))
+	}
+
+	m := migrator.New(pg.Open(os.Getenv({{quote .DSN}})), opts...)
+	m.AddSchema(source.{{.Source.Name}}...)
+
+	path, err := m.Generate(migrator.Scaffold{
+		Dir:     {{quote .Dir}},
+		Name:    {{quote .Name}},
+		Format:  migrator.ScaffoldFormat({{quote .Format}}),
+		Package: {{quote .Package}},
+	})
+	if errors.Is(err, migrator.ErrNoChanges) {
+		terminal.Warning("The database is in sync with the migrations, nothing was written")
+		return
+	}
+	if err != nil {
+		terminal.Error(err)
+		os.Exit(1)
+	}
+
+	terminal.Success("Created " + path)
+}
+`))
+
+// render returns the source of the generated program.
+func (p program) render() ([]byte, error) {
+	var buf bytes.Buffer
+	err := programTemplate.Execute(&buf, struct {
+		program
+		DSN string
+	}{p, dsnVariable})
+	return buf.Bytes(), err
+}
+
+// generate builds and runs, with go run, a program in the module of the
+// working directory that registers the migrations of the application and
+// calls Generate, then removes it. The program lives in a hidden directory of
+// the working directory so it resolves the application's imports.
+//
+// Parameters:
+//   - p: the values of the program
+//   - dsn: the connection string of the database to compare with
+//
+// Returns:
+//   - error: if the program cannot be written or fails
+func generate(p program, dsn string) error {
+	if dsn == "" {
+		return errors.New("a connection string is required, set -dsn or DATABASE_URL")
+	}
+
+	content, err := p.render()
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp(".", ".migration-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), content, 0o644); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", "run", "./"+filepath.ToSlash(dir))
+	cmd.Env = append(os.Environ(), dsnVariable+"="+dsn)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"go/format"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseSource tests reading the variable holding the registered migrations.
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		name     string // name of the test case
+		text     string // the flag value
+		expected source // the expected source, when valid
+		valid    bool   // whether the value is accepted
+	}{
+		{name: "Package variable", text: "example.com/app/migrations.All", expected: source{Import: "example.com/app/migrations", Name: "All"}, valid: true},
+		{name: "Dotted module path", text: "github.com/acme/app.v2/db.Migrations", expected: source{Import: "github.com/acme/app.v2/db", Name: "Migrations"}, valid: true},
+		{name: "Missing variable", text: "example.com/app/migrations"},
+		{name: "Unexported variable", text: "example.com/app/migrations.all"},
+		{name: "Missing import path", text: ".All"},
+	}
+
+	for _, item := range tests {
+		t.Run(item.name, func(t *testing.T) {
+			s, err := parseSource(item.text)
+			if !item.valid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, item.expected, s)
+		})
+	}
+}
+
+// TestRender tests the source of the program that calls Generate.
+func TestRender(t *testing.T) {
+	p := program{
+		Source:  source{Import: "example.com/app/migrations", Name: "All"},
+		Schema:  "tenant",
+		Dir:     "migrations",
+		Name:    `add "profile"`,
+		Format:  "sql",
+		Package: "migrations",
+	}
+
+	content, err := p.render()
+	require.NoError(t, err)
+
+	formatted, err := format.Source(content)
+	require.NoError(t, err)
+	assert.Equal(t, string(formatted), string(content))
+
+	src := string(content)
+	assert.Contains(t, src, `source "example.com/app/migrations"`)
+	assert.Contains(t, src, "m.AddSchema(source.All...)")
+	assert.Contains(t, src, `os.Getenv("GOUTIL_MIGRATION_DSN")`)
+	assert.Contains(t, src, `Name:    "add \"profile\"",`)
+	assert.Contains(t, src, `if schema := "tenant"; schema != ""`)
+}
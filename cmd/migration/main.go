@@ -0,0 +1,79 @@
+// Command migration writes a new migration file with a timestamped code.
+//
+// Usage:
+//
+//	go run github.com/pinzlab/goutil/cmd/migration -name "add profile" [-dir migrations] [-format go|sql] [-package migrations]
+//	go run github.com/pinzlab/goutil/cmd/migration -name "add profile" -migrations example.com/app/migrations.All [-dsn postgres://...] [-schema tenant]
+//
+// With -migrations, the command compares the migrations registered in the given
+// exported []migrator.Migration variable with the database, as the Generate
+// method of the migrator does, and writes a migration with the differences. It
+// must run from the module of the application, since it builds a small program
+// there that imports the variable; the connection string is read from -dsn or
+// the DATABASE_URL environment variable. When the database is in sync, nothing
+// is written.
+//
+// Without -migrations, the file is written without statements, to be completed
+// by hand.
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"os/exec"
+
+	"github.com/pinzlab/goutil/pg/migrator"
+	"github.com/pinzlab/goutil/terminal"
+)
+
+func main() {
+	dir := flag.String("dir", "migrations", "directory where the migration is written")
+	name := flag.String("name", "", "human-readable name of the migration")
+	format := flag.String("format", string(migrator.ScaffoldGo), "kind of file to write: go or sql")
+	pkg := flag.String("package", "", "package of a Go migration, the directory name when empty")
+	migrations := flag.String("migrations", "", "exported variable with the registered migrations, e.g. example.com/app/migrations.All")
+	dsn := flag.String("dsn", os.Getenv("DATABASE_URL"), "connection string of the database, DATABASE_URL when empty")
+	schema := flag.String("schema", "", "schema compared with the migrations, the current schema when empty")
+	flag.Parse()
+
+	if *pkg == "" {
+		*pkg = packageName(*dir)
+	}
+
+	if *migrations != "" {
+		src, err := parseSource(*migrations)
+		if err == nil {
+			err = generate(program{
+				Source:  src,
+				Schema:  *schema,
+				Dir:     *dir,
+				Name:    *name,
+				Format:  *format,
+				Package: *pkg,
+			}, *dsn)
+		}
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.ExitCode()) // the program already reported its error
+		}
+		if err != nil {
+			terminal.Error(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	path, err := migrator.GenerateEmpty(migrator.Scaffold{
+		Dir:     *dir,
+		Name:    *name,
+		Format:  migrator.ScaffoldFormat(*format),
+		Package: *pkg,
+	})
+	if err != nil {
+		terminal.Error(err)
+		os.Exit(1)
+	}
+
+	terminal.Success("Created " + path)
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// packageName derives a Go package name from the directory of the migration.
+func packageName(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "migrations"
+	}
+
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, strings.ToLower(filepath.Base(abs)))
+
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return "migrations"
+	}
+	return name
+}
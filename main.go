@@ -33,7 +33,7 @@ type Profile struct {
 type GQLProfile struct {
 	UserID      string
 	ProfileType GQLProfileType
-	Document    string
+	Doc         string `track:"Document,required"`
 }
 
 func main() {
@@ -42,11 +42,18 @@ func main() {
 	gql := GQLProfile{
 		UserID:      "1",
 		ProfileType: GQLProfilePersonal,
-		Document:    "0604059741",
+		Doc:         "0604059741",
 	}
 
 	track.ToCreate(&gql, &profile, nil)
 
 	fmt.Printf("%+v\n\n", profile)
 
+	var output GQLProfile
+	if err := track.ToOutput(&profile, &output); err != nil {
+		fmt.Println(err)
+	}
+
+	fmt.Printf("%+v\n\n", output)
+
 }
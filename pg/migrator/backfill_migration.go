@@ -0,0 +1,127 @@
+package migrator
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultBatchSize is the number of rows processed by each batch of a
+// BackfillMigration that does not set its Size.
+const DefaultBatchSize = 1000
+
+// BackfillMigration defines a long-running data migration, such as filling a
+// new column of a large table, that processes rows in batches. Each batch runs
+// in its own transaction together with the update of its cursor in the tracking
+// table, so locks are held briefly and an interrupted run resumes after the
+// last committed batch. The migration is recorded as applied after the last one.
+//
+// Batches are written either as a SQL Script or as a Go Step function. The
+// script receives the named arguments @cursor and @size and returns the cursor
+// of each processed row, ordered, as its only column; the batch is the last one
+// when it returns fewer rows than the size. For example:
+//
+//	WITH batch AS (
+//		SELECT id FROM users WHERE id > COALESCE(NULLIF(@cursor, '')::bigint, 0)
+//		ORDER BY id LIMIT @size
+//	)
+//	UPDATE users SET slug = lower(name) FROM batch WHERE users.id = batch.id
+//	RETURNING users.id::text
+//
+// The cursor of the first batch is the empty string.
+type BackfillMigration struct {
+	Code        string        // Unique code identifier for the BackfillMigration (max 20 chars)
+	Name        string        // Human-readable name for the BackfillMigration (max 100 chars)
+	Description string        // Optional detailed description of the BackfillMigration (max 255 chars)
+	Requires    []string      // Codes of the migrations that must be applied before this one
+	Script      string        // SQL that processes one batch, see BackfillMigration
+	Step        BatchFunc     // Function that processes one batch, used when Script is empty
+	Size        int           // Rows per batch, DefaultBatchSize when zero
+	Pause       time.Duration // Time to wait between batches, to throttle the load on the database
+}
+
+// BatchFunc processes the batch of at most size rows after cursor and returns
+// the cursor of the last processed row, and whether no rows remain.
+type BatchFunc func(tx *gorm.DB, cursor string, size int) (next string, done bool, err error)
+
+// GetCode returns the unique identifier for the migration
+func (m *BackfillMigration) GetCode() string {
+	return m.Code
+}
+
+// GetName returns the human-readable name for the migration
+func (m *BackfillMigration) GetName() string {
+	return m.Name
+}
+
+// GetDescription returns the detailed description for the migration
+func (m *BackfillMigration) GetDescription() string {
+	return m.Description
+}
+
+// GetRequires returns the codes of the migrations that must be applied first
+func (m *BackfillMigration) GetRequires() []string {
+	return m.Requires
+}
+
+// Execute processes every batch on the given session, one after the other.
+// The migrator calls ExecuteBatch instead, to commit each batch on its own.
+func (m *BackfillMigration) Execute(tx *gorm.DB) error {
+	cursor := ""
+	for {
+		next, done, err := m.ExecuteBatch(tx, cursor)
+		if err != nil || done {
+			return err
+		}
+		cursor = next
+	}
+}
+
+// ExecuteBatch processes the batch after the given cursor and returns the cursor
+// of its last row, and whether it was the last batch. On a dry-run session,
+// where queries return no rows, the script or the step runs once and is
+// reported as the last batch.
+func (m *BackfillMigration) ExecuteBatch(tx *gorm.DB, cursor string) (string, bool, error) {
+	if m.Script == "" {
+		if m.Step == nil {
+			return cursor, true, nil
+		}
+		next, done, err := m.Step(tx, cursor, m.size())
+		if tx != nil && tx.DryRun {
+			return cursor, true, err
+		}
+		return next, done, err
+	}
+
+	args := map[string]any{"cursor": cursor, "size": m.size()}
+	if tx.DryRun {
+		return cursor, true, tx.Exec(m.Script, args).Error
+	}
+
+	var cursors []string
+	if err := tx.Raw(m.Script, args).Scan(&cursors).Error; err != nil {
+		return cursor, false, err
+	}
+	if len(cursors) == 0 {
+		return cursor, true, nil
+	}
+	return cursors[len(cursors)-1], len(cursors) < m.size(), nil
+}
+
+// Throttle returns the time to wait between batches
+func (m *BackfillMigration) Throttle() time.Duration {
+	return m.Pause
+}
+
+// Checksum returns a hash of the batch script
+func (m *BackfillMigration) Checksum() string {
+	return checksum(m.Script)
+}
+
+// size returns the number of rows per batch.
+func (m *BackfillMigration) size() int {
+	if m.Size <= 0 {
+		return DefaultBatchSize
+	}
+	return m.Size
+}
@@ -0,0 +1,77 @@
+package migrator
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestBackfillStep tests that a backfill migration processes its batches with the returned cursors.
+func TestBackfillStep(t *testing.T) {
+	var cursors []string
+	migration := &BackfillMigration{
+		Code: "0010",
+		Size: 2,
+		Step: func(tx *gorm.DB, cursor string, size int) (string, bool, error) {
+			cursors = append(cursors, cursor)
+			last, _ := strconv.Atoi("0" + cursor)
+			return strconv.Itoa(last + size), last+size >= 5, nil
+		},
+	}
+
+	require.NoError(t, migration.Execute(nil))
+	assert.Equal(t, []string{"", "2", "4"}, cursors)
+
+	next, done, err := migration.ExecuteBatch(nil, "2")
+	require.NoError(t, err)
+	assert.Equal(t, "4", next)
+	assert.False(t, done)
+}
+
+// TestBackfillScript tests that a backfill script is recorded once with its arguments on a dry-run session.
+func TestBackfillScript(t *testing.T) {
+	migration := &BackfillMigration{
+		Code:   "0010",
+		Script: "UPDATE users SET slug = lower(name) WHERE id > @cursor LIMIT @size RETURNING id::text",
+	}
+
+	rec := newRecorder()
+	require.NoError(t, migration.Execute(dryRun(t, rec)))
+	assert.Equal(t, []string{"UPDATE users SET slug = lower(name) WHERE id > '' LIMIT 1000 RETURNING id::text"}, rec.statements)
+
+	assert.Equal(t, checksum(migration.Script), migration.Checksum())
+	var _ Batched = migration
+}
+
+// TestBackfillStepDryRun tests that a step that never reports done runs once on a dry-run session.
+func TestBackfillStepDryRun(t *testing.T) {
+	calls := 0
+	migration := &BackfillMigration{
+		Code: "0011",
+		Step: func(tx *gorm.DB, cursor string, size int) (string, bool, error) {
+			calls++
+			return cursor, false, tx.Exec("UPDATE users SET slug = lower(name) WHERE id > ?", cursor).Error
+		},
+	}
+
+	rec := newRecorder()
+	require.NoError(t, migration.Execute(dryRun(t, rec)))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []string{"UPDATE users SET slug = lower(name) WHERE id > ''"}, rec.statements)
+}
+
+// TestBackfillSize tests the number of rows per batch.
+func TestBackfillSize(t *testing.T) {
+	assert.Equal(t, DefaultBatchSize, (&BackfillMigration{}).size())
+	assert.Equal(t, 250, (&BackfillMigration{Size: 250}).size())
+}
+
+// TestTrackerDone tests that records with a cursor belong to migrations in progress.
+func TestTrackerDone(t *testing.T) {
+	cursor := "42"
+	assert.True(t, (&tracker{Code: "0001"}).done())
+	assert.False(t, (&tracker{Code: "0010", Cursor: &cursor}).done())
+}
@@ -0,0 +1,130 @@
+package migrator
+
+import (
+	"fmt"
+
+	"github.com/pinzlab/goutil/terminal"
+	"gorm.io/gorm"
+)
+
+// Baseline adopts the migrator on an existing database whose schema already
+// matches the first migrations. It creates the tracking table if needed and
+// records every migration that Run applies up to, and including, the one
+// identified by code as applied, without executing them. Migrations already
+// recorded are left untouched, so Baseline can be repeated safely.
+//
+// Like Run, Baseline holds the migrator's advisory lock.
+//
+// Parameters:
+//   - upToCode: the code of the last migration already present in the database
+//
+// Returns:
+//   - error: if the code is not registered or the tracking table cannot be written
+func (m *migrator) Baseline(upToCode string) error {
+	migrations, err := m.ordered()
+	if err != nil {
+		return err
+	}
+
+	target := find(migrations, upToCode)
+	if target < 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownMigration, upToCode)
+	}
+
+	return m.locked(func() error {
+		return m.mark(migrations[:target+1])
+	})
+}
+
+// MarkApplied records the migrations identified by the given codes as applied,
+// without executing them, e.g. after applying their changes by hand. Migrations
+// already recorded are left untouched. The tracking table is created if needed.
+//
+// Parameters:
+//   - codes: the codes of registered migrations to record
+//
+// Returns:
+//   - error: if a code is not registered or the tracking table cannot be written
+func (m *migrator) MarkApplied(codes ...string) error {
+	migrations, err := m.ordered()
+	if err != nil {
+		return err
+	}
+
+	selected := make([]Migration, 0, len(codes))
+	for _, code := range codes {
+		index := find(migrations, code)
+		if index < 0 {
+			return fmt.Errorf("%w: %s", ErrUnknownMigration, code)
+		}
+		selected = append(selected, migrations[index])
+	}
+
+	return m.locked(func() error {
+		return m.mark(selected)
+	})
+}
+
+// MarkPending removes the records of the given codes from the tracking table,
+// without reverting their changes, so Run applies the migrations again, e.g.
+// after undoing a migration by hand. Codes that are not registered, such as
+// those of deleted migrations, are removed as well; codes without a record
+// are ignored.
+//
+// Parameters:
+//   - codes: the codes of the records to remove
+//
+// Returns:
+//   - error: if the tracking table cannot be written
+func (m *migrator) MarkPending(codes ...string) error {
+	return m.locked(func() error {
+		te, err := m.trackerExists()
+		if err != nil || !te || len(codes) == 0 {
+			return err
+		}
+
+		for _, code := range codes {
+			terminal.About("Pending", code)
+		}
+		return m.track(m.db).Where("code IN ?", codes).Delete(&tracker{}).Error
+	})
+}
+
+// mark records the given migrations as applied in a single transaction,
+// skipping those already recorded and finishing batched ones in progress.
+func (m *migrator) mark(migrations []Migration) error {
+	if err := m.migrateTracker(); err != nil {
+		return err
+	}
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		for _, migration := range migrations {
+			var records []tracker
+			if err := m.track(tx).Where("code = ?", migration.GetCode()).Find(&records).Error; err != nil {
+				return err
+			}
+			if len(records) > 0 && records[0].done() {
+				continue
+			}
+
+			terminal.About("Mark", migration.GetName())
+			if err := m.track(tx).Where("code = ?", migration.GetCode()).Delete(&tracker{}).Error; err != nil {
+				return err
+			}
+			if err := m.track(tx).Create(newTracker(migration)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// find returns the index of the migration identified by code, or -1 if it is not in the list.
+func find(migrations []Migration, code string) int {
+	for index, migration := range migrations {
+		if migration.GetCode() == code {
+			return index
+		}
+	}
+	return -1
+}
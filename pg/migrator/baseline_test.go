@@ -0,0 +1,28 @@
+package migrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFind tests looking up a migration by code.
+func TestFind(t *testing.T) {
+	migrations := []Migration{
+		&SQLMigration{Code: "0001"},
+		&SQLMigration{Code: "0002"},
+	}
+
+	assert.Equal(t, 0, find(migrations, "0001"))
+	assert.Equal(t, 1, find(migrations, "0002"))
+	assert.Equal(t, -1, find(migrations, "0003"))
+}
+
+// TestMarkUnknown tests that unregistered codes are reported before touching the database.
+func TestMarkUnknown(t *testing.T) {
+	m := New(nil)
+	m.AddSchema(&SQLMigration{Code: "0001"}, &SQLMigration{Code: "0002"})
+
+	assert.ErrorIs(t, m.Baseline("0003"), ErrUnknownMigration)
+	assert.ErrorIs(t, m.MarkApplied("0001", "0003"), ErrUnknownMigration)
+}
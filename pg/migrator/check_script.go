@@ -0,0 +1,42 @@
+package migrator
+
+import "strings"
+
+// Check represents a CHECK constraint on a table.
+type Check struct {
+	Table      string // Name of the table
+	Name       string // Name of the constraint, derived from the table and the expression when empty
+	Expression string // Boolean SQL expression every row must satisfy, e.g. "price >= 0"
+	Schema     string // Optional schema of the table, resolved through search_path when empty
+}
+
+// GetScript generates a SQL script that adds the CHECK constraint to the table
+// only if a constraint with the same name does not already exist on it.
+func (c *Check) GetScript() string {
+	name := c.name()
+	table := qualify(c.Schema, c.Table)
+
+	return `
+	DO $$ BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = '` + name + `' AND conrelid = '` + table + `'::regclass) THEN
+			ALTER TABLE ` + table + ` ADD CONSTRAINT ` + name + ` CHECK (` + c.Expression + `);
+		END IF;
+	END $$;
+	`
+}
+
+// name returns the name of the constraint. The default name joins the table
+// and the words of the expression, followed by a hash of the expression so that
+// expressions differing only in operators, like "price > 0" and "price >= 0",
+// do not share a name, and kept within the 63 bytes allowed by PostgreSQL.
+func (c *Check) name() string {
+	if c.Name != "" {
+		return c.Name
+	}
+
+	name := identifier("chk", c.Table, c.Expression)
+	if len(name) > 54 {
+		name = strings.TrimSuffix(name[:54], "_")
+	}
+	return name + "_" + checksum(c.Expression)[:8]
+}
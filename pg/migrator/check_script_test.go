@@ -0,0 +1,65 @@
+package migrator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGetCheckScript tests the GetScript method of the Check struct.
+func TestGetCheckScript(t *testing.T) {
+	tests := []struct {
+		name     string // name of the test case
+		check    Check  // the Check object to test
+		expected string // the expected SQL script
+	}{
+		{
+			name: "Named check constraint",
+			check: Check{
+				Table:      "products",
+				Name:       "chk_products_price",
+				Expression: "price >= 0",
+			},
+			expected: `
+	DO $$ BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'chk_products_price' AND conrelid = 'products'::regclass) THEN
+			ALTER TABLE products ADD CONSTRAINT chk_products_price CHECK (price >= 0);
+		END IF;
+	END $$;
+	`,
+		},
+		{
+			name: "Default name in a schema",
+			check: Check{
+				Table:      "orders",
+				Expression: "quantity > 0",
+				Schema:     "tenant",
+			},
+			expected: `
+	DO $$ BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'chk_orders_quantity_0_955187f6' AND conrelid = 'tenant.orders'::regclass) THEN
+			ALTER TABLE tenant.orders ADD CONSTRAINT chk_orders_quantity_0_955187f6 CHECK (quantity > 0);
+		END IF;
+	END $$;
+	`,
+		},
+	}
+
+	for _, item := range tests {
+		t.Run(item.name, func(t *testing.T) {
+			assert.Equal(t, item.expected, item.check.GetScript())
+		})
+	}
+}
+
+// TestCheckDefaultName tests that unnamed checks on the same table get distinct names.
+func TestCheckDefaultName(t *testing.T) {
+	positive := Check{Table: "product", Expression: "price > 0"}
+	nonNegative := Check{Table: "product", Expression: "price >= 0"}
+	long := Check{Table: "product", Expression: strings.Repeat("description <> '' AND ", 5) + "price > 0"}
+
+	assert.Equal(t, "chk_product_price_0_dc183a97", positive.name())
+	assert.NotEqual(t, positive.name(), nonNegative.name())
+	assert.LessOrEqual(t, len(long.name()), 63)
+}
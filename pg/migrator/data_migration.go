@@ -12,12 +12,16 @@ import (
 // must be executed first, followed by other components like enums, entities,
 // unique indexes, foreign keys, stored procedures, and data insertions.
 //
-// Once all operations are successful, the DataMigration is recorded in a tracking table.
+// Once all operations are successful, the migrator records the DataMigration in a tracking table.
 type DataMigration struct {
 	Code        string    // Unique code identifier for the DataMigration (max 20 chars)
 	Name        string    // Human-readable name for the DataMigration (max 100 chars)
-	Description string    // Optional detailed description of the DataMigration (max 255 chars
+	Description string    // Optional detailed description of the DataMigration (max 255 chars)
+	Requires    []string  // Codes of the migrations that must be applied before this one
 	Data        []*Entity // Initial data to seed conditionally
+	Seeds       []*Seed   // Initial data to seed conditionally from CSV, JSON or YAML files
+	Repeatable  bool      // Whether to apply the DataMigration again whenever its data changes
+	Down        []string  // Raw SQL statements that revert the DataMigration, run in order on rollback
 }
 
 // GetCode returns the unique identifier for the migration
@@ -30,6 +34,21 @@ func (m *DataMigration) GetName() string {
 	return m.Name
 }
 
+// GetDescription returns the detailed description for the migration
+func (m *DataMigration) GetDescription() string {
+	return m.Description
+}
+
+// GetRequires returns the codes of the migrations that must be applied first
+func (m *DataMigration) GetRequires() []string {
+	return m.Requires
+}
+
+// IsRepeatable reports whether the data migration is applied again when its data changes
+func (m *DataMigration) IsRepeatable() bool {
+	return m.Repeatable
+}
+
 // Execute performs the data migration within a transaction
 func (m *DataMigration) Execute(tx *gorm.DB) error {
 	// Insert default data
@@ -39,9 +58,37 @@ func (m *DataMigration) Execute(tx *gorm.DB) error {
 		}
 	}
 
-	// Save the migration record to the tracking table.
-	if err := tx.Create(&tracker{Code: m.Code, Name: m.Name, Description: m.Description}).Error; err != nil {
-		return err
+	// Insert data from seed files, batch by batch
+	for _, seed := range m.Seeds {
+		err := seed.Each(func(data *Entity) error {
+			return tx.Exec(data.GetScript()).Error
+		})
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
+
+// CanRollback reports whether the data migration defines down steps
+func (m *DataMigration) CanRollback() bool {
+	return len(m.Down) > 0
+}
+
+// Rollback reverts the data migration within a transaction by executing its down steps
+func (m *DataMigration) Rollback(tx *gorm.DB) error {
+	return rollback(tx, m.Code, m.Down)
+}
+
+// Checksum returns a hash of the rows declared by the data migration entities
+// and of the content of its seed files.
+func (m *DataMigration) Checksum() string {
+	var scripts []string
+	for _, data := range m.Data {
+		scripts = append(scripts, declared(data))
+	}
+	for _, seed := range m.Seeds {
+		scripts = append(scripts, seed.checksum())
+	}
+	return checksum(scripts...)
+}
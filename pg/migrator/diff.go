@@ -0,0 +1,503 @@
+package migrator
+
+import (
+	"log"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pinzlab/goutil/terminal"
+	"gorm.io/gorm"
+	gormigrator "gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+)
+
+// DriftKind describes how the database differs from the registered migrations.
+type DriftKind string
+
+const (
+	DriftMissingTable      DriftKind = "Missing table"      // A table of an entity does not exist
+	DriftMissingColumn     DriftKind = "Missing column"     // A field of an entity has no column
+	DriftExtraColumn       DriftKind = "Extra column"       // A column has no field in its entity
+	DriftColumnType        DriftKind = "Column type"        // A column type differs from its field
+	DriftNullable          DriftKind = "Nullable"           // A column nullability differs from its field
+	DriftMissingIndex      DriftKind = "Missing index"      // An index of a Unique or Index builder does not exist
+	DriftMissingConstraint DriftKind = "Missing constraint" // A constraint of a Foreign or Check builder does not exist
+	DriftEnum              DriftKind = "Enum"               // An ENUM type is missing or its values differ
+)
+
+// Drift is a single difference between the database and the registered migrations.
+type Drift struct {
+	Kind     DriftKind // What kind of difference was found
+	Table    string    // Table of the difference, or the ENUM type name
+	Name     string    // Column, index or constraint name, empty for tables and ENUM types
+	Expected string    // Value declared by the migrations, e.g. a column type
+	Actual   string    // Value found in the database
+	SQL      string    // Suggested statement to fix the difference, commented out when destructive
+}
+
+// Diff is the list of differences returned by the migrator's Diff method.
+type Diff []Drift
+
+// column describes a table column, as declared by an entity or found in the database.
+type column struct {
+	name     string // Name of the column
+	dataType string // Type of the column, as formatted by PostgreSQL's format_type
+	nullable bool   // Whether the column accepts NULL values
+	add      string // Statement that adds the column, only for declared columns
+}
+
+// table describes a table declared by an entity.
+type table struct {
+	name    string      // Name of the table
+	columns []column    // Columns of the table, in field order
+	create  string      // Statement that creates the table
+	entity  interface{} // Entity that declares the table
+}
+
+// object describes an index or constraint declared by a builder.
+type object struct {
+	table  string // Table of the index or constraint
+	name   string // Name of the index or constraint
+	script string // Script of the builder that creates it
+}
+
+// expectation is the schema declared by the registered schema migrations.
+type expectation struct {
+	tables      []table
+	indexes     []object
+	constraints []object
+	enums       []*Enum
+}
+
+// catalog is a snapshot of the tables, indexes, constraints and ENUM types of a database schema.
+type catalog struct {
+	columns     map[string][]column // Columns by table, in column order
+	indexes     map[string]bool     // Index names
+	constraints map[string]bool     // Constraint names by table, as "table.name"
+	enums       map[string][]string // ENUM values by type, in sort order
+}
+
+// Diff compares the schema declared by the registered schema migrations with
+// the database: the tables and columns of their entities, parsed as GORM
+// models, the indexes and constraints of their Unique, Index, Foreign and
+// Check builders, and their ENUM types. Migrations of other kinds are ignored.
+//
+// Each difference comes with a suggested statement; the statements of
+// destructive fixes, such as dropping an extra column, are commented out.
+// Nothing is written to the database. When several migrations declare the
+// same entity or ENUM type, the last one in run order is compared.
+//
+// Returns:
+//   - Diff: the differences found, empty when the database is in sync
+//   - error: if the catalog cannot be queried or an entity cannot be parsed
+func (m *migrator) Diff() (diff Diff, err error) {
+	err = m.connected(func() error {
+		_, diff, err = m.diff()
+		return err
+	})
+	return diff, err
+}
+
+// diff compares the declared schema with the database, see Diff.
+func (m *migrator) diff() (expectation, Diff, error) {
+	expected, err := m.expect()
+	if err != nil {
+		return expectation{}, nil, err
+	}
+	actual, err := m.introspect()
+	if err != nil {
+		return expectation{}, nil, err
+	}
+	return expected, compare(expected, actual), nil
+}
+
+// expect builds the schema declared by the registered schema migrations.
+func (m *migrator) expect() (expectation, error) {
+	migrations, err := m.ordered()
+	if err != nil {
+		return expectation{}, err
+	}
+
+	expected := expectation{}
+	declared := map[string]int{}
+	enums := map[string]int{}
+	for _, migration := range migrations {
+		sm, ok := migration.(*SchemaMigration)
+		if !ok {
+			continue
+		}
+
+		for _, entity := range sm.Entities {
+			t, err := m.model(entity)
+			if err != nil {
+				return expectation{}, err
+			}
+			if index, ok := declared[t.name]; ok {
+				expected.tables[index] = t
+				continue
+			}
+			declared[t.name] = len(expected.tables)
+			expected.tables = append(expected.tables, t)
+		}
+
+		for _, unique := range sm.Uniques {
+			expected.indexes = append(expected.indexes, object{unique.Table, unique.name(), unique.GetScript()})
+		}
+		for _, index := range sm.Indexes {
+			expected.indexes = append(expected.indexes, object{index.Table, index.name(), index.GetScript()})
+		}
+		for _, fk := range sm.ForeignKeys {
+			expected.constraints = append(expected.constraints, object{fk.Table, fk.name(), fk.GetScript()})
+		}
+		for _, check := range sm.Checks {
+			expected.constraints = append(expected.constraints, object{check.Table, check.name(), check.GetScript()})
+		}
+		for _, enum := range sm.Enums {
+			name := qualify(enum.Schema, enum.Name)
+			if index, ok := enums[name]; ok {
+				expected.enums[index] = enum
+				continue
+			}
+			enums[name] = len(expected.enums)
+			expected.enums = append(expected.enums, enum)
+		}
+	}
+
+	return expected, nil
+}
+
+// model parses an entity into the table it declares, together with the
+// statements GORM would execute to create the table and each column.
+func (m *migrator) model(entity interface{}) (table, error) {
+	stmt := &gorm.Statement{DB: m.db}
+	if err := stmt.Parse(entity); err != nil {
+		return table{}, err
+	}
+
+	t := table{name: stmt.Table, entity: entity}
+	create, err := ddl(m.db, func(dry *gorm.DB) error {
+		return dry.Migrator().CreateTable(entity)
+	})
+	if err != nil {
+		return table{}, err
+	}
+	t.create = create
+
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || field.IgnoreMigration {
+			continue
+		}
+		add, err := ddl(m.db, func(dry *gorm.DB) error {
+			return dry.Migrator().AddColumn(entity, field.DBName)
+		})
+		if err != nil {
+			return table{}, err
+		}
+		t.columns = append(t.columns, column{
+			name:     field.DBName,
+			dataType: normalizeType(dataTypeOf(m.db, field)),
+			nullable: nullable(field),
+			add:      add,
+		})
+	}
+
+	return t, nil
+}
+
+// ddl returns the statements executed by fn on a dry-run session.
+func ddl(db *gorm.DB, fn func(dry *gorm.DB) error) (string, error) {
+	rec := newRecorder()
+	dry := db.Session(&gorm.Session{DryRun: true, SkipDefaultTransaction: true, Logger: rec})
+	if err := fn(dry); err != nil {
+		return "", err
+	}
+	return strings.Join(rec.statements, ";\n") + ";", nil
+}
+
+// dataTypeOf returns the column type GORM declares for a field, as its migrator does.
+func dataTypeOf(db *gorm.DB, field *schema.Field) string {
+	value := reflect.New(field.IndirectFieldType).Interface()
+	if typer, ok := value.(gormigrator.GormDataTypeInterface); ok {
+		if dataType := typer.GormDBDataType(db, field); dataType != "" {
+			return dataType
+		}
+	}
+	return db.Dialector.DataTypeOf(field)
+}
+
+// nullable reports whether the column of a field accepts NULL values.
+func nullable(field *schema.Field) bool {
+	return !field.NotNull && !field.PrimaryKey
+}
+
+// introspect reads the catalog of the target schema, or of the current schema when none is configured.
+func (m *migrator) introspect() (catalog, error) {
+	actual := catalog{
+		columns:     map[string][]column{},
+		indexes:     map[string]bool{},
+		constraints: map[string]bool{},
+		enums:       map[string][]string{},
+	}
+
+	var columns []struct {
+		TableName  string
+		ColumnName string
+		DataType   string
+		Nullable   bool
+	}
+	err := m.db.Raw(`
+		SELECT c.relname AS table_name, a.attname AS column_name,
+			format_type(a.atttypid, a.atttypmod) AS data_type, NOT a.attnotnull AS nullable
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = COALESCE(NULLIF(?, ''), current_schema())
+			AND c.relkind IN ('r', 'p') AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY c.relname, a.attnum`, m.dbSchema).Scan(&columns).Error
+	if err != nil {
+		return catalog{}, err
+	}
+	for _, c := range columns {
+		actual.columns[c.TableName] = append(actual.columns[c.TableName], column{
+			name:     c.ColumnName,
+			dataType: c.DataType,
+			nullable: c.Nullable,
+		})
+	}
+
+	var indexes []string
+	err = m.db.Raw(`
+		SELECT indexname FROM pg_indexes
+		WHERE schemaname = COALESCE(NULLIF(?, ''), current_schema())`, m.dbSchema).Scan(&indexes).Error
+	if err != nil {
+		return catalog{}, err
+	}
+	for _, index := range indexes {
+		actual.indexes[index] = true
+	}
+
+	var constraints []string
+	err = m.db.Raw(`
+		SELECT c.relname || '.' || con.conname FROM pg_constraint con
+		JOIN pg_class c ON c.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = COALESCE(NULLIF(?, ''), current_schema())`, m.dbSchema).Scan(&constraints).Error
+	if err != nil {
+		return catalog{}, err
+	}
+	for _, constraint := range constraints {
+		actual.constraints[constraint] = true
+	}
+
+	var labels []struct {
+		TypeName string
+		Label    string
+	}
+	err = m.db.Raw(`
+		SELECT t.typname AS type_name, e.enumlabel AS label FROM pg_enum e
+		JOIN pg_type t ON t.oid = e.enumtypid
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		WHERE n.nspname = COALESCE(NULLIF(?, ''), current_schema())
+		ORDER BY t.typname, e.enumsortorder`, m.dbSchema).Scan(&labels).Error
+	if err != nil {
+		return catalog{}, err
+	}
+	for _, label := range labels {
+		actual.enums[label.TypeName] = append(actual.enums[label.TypeName], label.Label)
+	}
+
+	return actual, nil
+}
+
+// compare lists the differences between the declared schema and the catalog of the database.
+func compare(expected expectation, actual catalog) Diff {
+	diff := Diff{}
+
+	for _, enum := range expected.enums {
+		values, ok := actual.enums[unqualified(enum.Name)]
+		if !ok {
+			diff = append(diff, Drift{Kind: DriftEnum, Table: enum.Name, Expected: strings.Join(enum.Values, ", "), SQL: enum.GetScript()})
+			continue
+		}
+		if changes := diffValues(enum.Values, values); len(changes.Added) > 0 || len(changes.Removed) > 0 {
+			diff = append(diff, Drift{
+				Kind:     DriftEnum,
+				Table:    enum.Name,
+				Expected: strings.Join(enum.Values, ", "),
+				Actual:   strings.Join(values, ", "),
+				SQL:      enum.GetUpdateScript(),
+			})
+		}
+	}
+
+	for _, t := range expected.tables {
+		columns, ok := actual.columns[unqualified(t.name)]
+		if !ok {
+			diff = append(diff, Drift{Kind: DriftMissingTable, Table: t.name, SQL: t.create})
+			continue
+		}
+
+		existing := make(map[string]column, len(columns))
+		for _, c := range columns {
+			existing[c.name] = c
+		}
+
+		declared := make(map[string]bool, len(t.columns))
+		for _, c := range t.columns {
+			declared[c.name] = true
+
+			found, ok := existing[c.name]
+			if !ok {
+				diff = append(diff, Drift{Kind: DriftMissingColumn, Table: t.name, Name: c.name, Expected: c.dataType, SQL: c.add})
+				continue
+			}
+			if found.dataType != c.dataType {
+				diff = append(diff, Drift{
+					Kind:     DriftColumnType,
+					Table:    t.name,
+					Name:     c.name,
+					Expected: c.dataType,
+					Actual:   found.dataType,
+					SQL:      "ALTER TABLE " + t.name + " ALTER COLUMN " + c.name + " TYPE " + c.dataType + " USING " + c.name + "::" + c.dataType + ";",
+				})
+			}
+			if found.nullable != c.nullable {
+				change := "SET NOT NULL"
+				if c.nullable {
+					change = "DROP NOT NULL"
+				}
+				diff = append(diff, Drift{
+					Kind:     DriftNullable,
+					Table:    t.name,
+					Name:     c.name,
+					Expected: nullability(c.nullable),
+					Actual:   nullability(found.nullable),
+					SQL:      "ALTER TABLE " + t.name + " ALTER COLUMN " + c.name + " " + change + ";",
+				})
+			}
+		}
+
+		for _, c := range columns {
+			if !declared[c.name] {
+				diff = append(diff, Drift{
+					Kind:   DriftExtraColumn,
+					Table:  t.name,
+					Name:   c.name,
+					Actual: c.dataType,
+					SQL:    "-- ALTER TABLE " + t.name + " DROP COLUMN " + c.name + ";",
+				})
+			}
+		}
+	}
+
+	for _, index := range expected.indexes {
+		if !actual.indexes[index.name] {
+			diff = append(diff, Drift{Kind: DriftMissingIndex, Table: index.table, Name: index.name, SQL: index.script})
+		}
+	}
+
+	for _, constraint := range expected.constraints {
+		if !actual.constraints[unqualified(constraint.table)+"."+constraint.name] {
+			diff = append(diff, Drift{Kind: DriftMissingConstraint, Table: constraint.table, Name: constraint.name, SQL: constraint.script})
+		}
+	}
+
+	return diff
+}
+
+// unqualified returns a name without its schema.
+func unqualified(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// nullability describes whether a column accepts NULL values.
+func nullability(nullable bool) string {
+	if nullable {
+		return "NULL"
+	}
+	return "NOT NULL"
+}
+
+// sized matches the type names that take a size or precision, e.g. varchar(100) or timestamptz(3).
+var sized = regexp.MustCompile(`^([a-z][a-z0-9_ ]*?)\s*(\([0-9, ]+\))?$`)
+
+// aliases maps the type names used by GORM and in struct tags to the names formatted by PostgreSQL.
+var aliases = map[string]string{
+	"smallserial": "smallint",
+	"serial":      "integer",
+	"bigserial":   "bigint",
+	"int2":        "smallint",
+	"int":         "integer",
+	"int4":        "integer",
+	"int8":        "bigint",
+	"float4":      "real",
+	"float8":      "double precision",
+	"bool":        "boolean",
+	"decimal":     "numeric",
+	"varchar":     "character varying",
+	"char":        "character",
+	"timestamp":   "timestamp without time zone",
+	"timestamptz": "timestamp with time zone",
+	"time":        "time without time zone",
+	"timetz":      "time with time zone",
+}
+
+// normalizeType converts a declared column type to the form returned by PostgreSQL's
+// format_type, e.g. varchar(100) becomes character varying(100) and bigserial becomes
+// bigint, the type of the column it creates.
+func normalizeType(dataType string) string {
+	dataType = strings.ToLower(strings.TrimSpace(dataType))
+
+	match := sized.FindStringSubmatch(dataType)
+	if match == nil {
+		return dataType
+	}
+
+	name, size := match[1], strings.ReplaceAll(match[2], " ", "")
+	if alias, ok := aliases[name]; ok {
+		name = alias
+	}
+
+	// PostgreSQL places the precision of time types before the time zone
+	if strings.HasPrefix(name, "time") && strings.Contains(name, " ") && size != "" {
+		head, tail, _ := strings.Cut(name, " ")
+		return head + size + " " + tail
+	}
+	return name + size
+}
+
+// SQL returns the suggested statements of the differences, one after the other,
+// as the body of a migration that brings the database in sync with the code.
+func (d Diff) SQL() string {
+	statements := make([]string, 0, len(d))
+	for _, drift := range d {
+		if drift.SQL != "" {
+			statements = append(statements, strings.TrimSpace(drift.SQL))
+		}
+	}
+	return strings.Join(statements, "\n\n")
+}
+
+// Print writes the differences to the log through the terminal package,
+// followed by their suggested statements.
+func (d Diff) Print() {
+	if len(d) == 0 {
+		terminal.Info("The database is in sync with the migrations")
+		return
+	}
+
+	for _, drift := range d {
+		msg := drift.Table
+		if drift.Name != "" {
+			msg += "." + drift.Name
+		}
+		if drift.Expected != "" || drift.Actual != "" {
+			msg += " (expected: " + drift.Expected + ", actual: " + drift.Actual + ")"
+		}
+		log.Println(terminal.Alert(terminal.BgYellow, string(drift.Kind), msg))
+		if drift.SQL != "" {
+			terminal.Script(strings.TrimSpace(drift.SQL))
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package migrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// diffUser is the entity used to test the declared schema.
+type diffUser struct {
+	ID   int64  `gorm:"primaryKey"`
+	Name string `gorm:"size:100;not null"`
+	Role string `gorm:"type:role_enum"`
+}
+
+// TestNormalizeType tests the conversion of declared types to the form returned by format_type.
+func TestNormalizeType(t *testing.T) {
+	tests := []struct {
+		declared string // the type declared by GORM or a struct tag
+		expected string // the type as formatted by PostgreSQL
+	}{
+		{declared: "bigserial", expected: "bigint"},
+		{declared: "int8", expected: "bigint"},
+		{declared: "varchar(100)", expected: "character varying(100)"},
+		{declared: "VARCHAR", expected: "character varying"},
+		{declared: "decimal", expected: "numeric"},
+		{declared: "numeric(10, 2)", expected: "numeric(10,2)"},
+		{declared: "timestamptz", expected: "timestamp with time zone"},
+		{declared: "timestamptz(3)", expected: "timestamp(3) with time zone"},
+		{declared: "bool", expected: "boolean"},
+		{declared: "role_enum", expected: "role_enum"},
+		{declared: "text[]", expected: "text[]"},
+	}
+
+	for _, item := range tests {
+		t.Run(item.declared, func(t *testing.T) {
+			assert.Equal(t, item.expected, normalizeType(item.declared))
+		})
+	}
+}
+
+// TestModel tests parsing an entity into its declared table and statements.
+func TestModel(t *testing.T) {
+	m := New(dryRun(t, newRecorder()))
+
+	model, err := m.model(&diffUser{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "diff_users", model.name)
+	assert.Contains(t, model.create, `CREATE TABLE "diff_users"`)
+	require.Len(t, model.columns, 3)
+	assert.Equal(t, column{name: "id", dataType: "bigint", add: `ALTER TABLE "diff_users" ADD "id" bigserial;`}, model.columns[0])
+	assert.Equal(t, "character varying(100)", model.columns[1].dataType)
+	assert.False(t, model.columns[1].nullable)
+	assert.Equal(t, "role_enum", model.columns[2].dataType)
+	assert.True(t, model.columns[2].nullable)
+}
+
+// TestCompare tests the differences found between the declared schema and the database catalog.
+func TestCompare(t *testing.T) {
+	enum := &Enum{Name: "role_enum", Values: []string{"admin", "guest"}}
+	unique := &Unique{Table: "users", Columns: []string{"email"}}
+	fk := &Foreign{Table: "users", ForeignID: "team_id", Reference: "teams", ReferenceID: "id"}
+	expected := expectation{
+		tables: []table{
+			{name: "users", columns: []column{
+				{name: "id", dataType: "bigint"},
+				{name: "email", dataType: "character varying(100)", add: "ALTER TABLE users ADD email varchar(100);"},
+				{name: "role", dataType: "role_enum", nullable: true},
+				{name: "age", dataType: "integer", nullable: true},
+			}},
+			{name: "teams", create: "CREATE TABLE teams (id bigserial);"},
+		},
+		indexes:     []object{{"users", unique.name(), unique.GetScript()}},
+		constraints: []object{{"users", fk.name(), fk.GetScript()}},
+		enums:       []*Enum{enum},
+	}
+	actual := catalog{
+		columns: map[string][]column{
+			"users": {
+				{name: "id", dataType: "bigint"},
+				{name: "role", dataType: "role_enum"},
+				{name: "age", dataType: "bigint", nullable: true},
+				{name: "legacy", dataType: "text", nullable: true},
+			},
+		},
+		indexes:     map[string]bool{},
+		constraints: map[string]bool{},
+		enums:       map[string][]string{"role_enum": {"admin"}},
+	}
+
+	diff := compare(expected, actual)
+
+	assert.Equal(t, Diff{
+		{Kind: DriftEnum, Table: "role_enum", Expected: "admin, guest", Actual: "admin", SQL: enum.GetUpdateScript()},
+		{Kind: DriftMissingColumn, Table: "users", Name: "email", Expected: "character varying(100)", SQL: "ALTER TABLE users ADD email varchar(100);"},
+		{Kind: DriftNullable, Table: "users", Name: "role", Expected: "NULL", Actual: "NOT NULL", SQL: "ALTER TABLE users ALTER COLUMN role DROP NOT NULL;"},
+		{Kind: DriftColumnType, Table: "users", Name: "age", Expected: "integer", Actual: "bigint", SQL: "ALTER TABLE users ALTER COLUMN age TYPE integer USING age::integer;"},
+		{Kind: DriftExtraColumn, Table: "users", Name: "legacy", Actual: "text", SQL: "-- ALTER TABLE users DROP COLUMN legacy;"},
+		{Kind: DriftMissingTable, Table: "teams", SQL: "CREATE TABLE teams (id bigserial);"},
+		{Kind: DriftMissingIndex, Table: "users", Name: "uni_users_email", SQL: unique.GetScript()},
+		{Kind: DriftMissingConstraint, Table: "users", Name: "fk_users_team_id", SQL: fk.GetScript()},
+	}, diff)
+
+	actual.indexes["uni_users_email"] = true
+	actual.constraints["users.fk_users_team_id"] = true
+	actual.enums["role_enum"] = []string{"admin", "guest"}
+	assert.Len(t, compare(expected, actual), 5)
+}
+
+// TestExpectRedeclaredEnum tests that only the last declaration of an enum,
+// in run order, is compared with the database.
+func TestExpectRedeclaredEnum(t *testing.T) {
+	m := New(dryRun(t, newRecorder()))
+	m.AddSchema(
+		&SchemaMigration{Code: "001", Enums: []*Enum{{Name: "status", Values: []string{"active", "inactive"}}}},
+		&SchemaMigration{Code: "002", Enums: []*Enum{{Name: "status", Values: []string{"active", "pending", "inactive"}}}},
+	)
+
+	expected, err := m.expect()
+	require.NoError(t, err)
+	require.Len(t, expected.enums, 1)
+	assert.Equal(t, []string{"active", "pending", "inactive"}, expected.enums[0].Values)
+
+	actual := catalog{enums: map[string][]string{"status": {"active", "pending", "inactive"}}}
+	assert.Empty(t, compare(expected, actual))
+}
+
+// TestDiffSQL tests joining the suggested statements of a diff.
+func TestDiffSQL(t *testing.T) {
+	diff := Diff{
+		{Kind: DriftMissingColumn, SQL: "\n\tALTER TABLE users ADD email text;\n"},
+		{Kind: DriftColumnType},
+		{Kind: DriftExtraColumn, SQL: "-- ALTER TABLE users DROP COLUMN legacy;"},
+	}
+
+	assert.Equal(t, "ALTER TABLE users ADD email text;\n\n-- ALTER TABLE users DROP COLUMN legacy;", diff.SQL())
+	assert.Equal(t, "", Diff{}.SQL())
+}
@@ -1,21 +1,148 @@
 package migrator
 
-import "strings"
+import (
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
 
 // Enum represents a PostgreSQL ENUM type, with its name and possible values.
+//
+// The values of an Enum are part of the checksum of its migration, so they must
+// not change once the migration is applied. To add or rename values, declare the
+// type again with the new values in a new migration: its GetScript does nothing
+// for the existing type and its GetUpdateScript evolves it.
 type Enum struct {
-	Name   string   // The name of the ENUM type
-	Values []string // The possible values of the ENUM type
+	Name    string            // The name of the ENUM type
+	Values  []string          // The possible values of the ENUM type, in order
+	Schema  string            // Optional schema of the ENUM type, the current schema when empty
+	Renames map[string]string // Values to rename in existing types, from the old value to the new one
+	Strict  bool              // Whether to fail when the existing type has values missing from Values
+}
+
+// EnumDiff describes how the values of an ENUM type in the database differ from its declaration.
+type EnumDiff struct {
+	Added   []string // Declared values missing from the database
+	Removed []string // Values in the database that are no longer declared
 }
 
-// Generates a SQL script that creates the ENUM type in PostgreSQL if it does not already exist.
-// The script uses a DO block to execute the SQL command conditionally.
+// Generates a SQL script that creates the ENUM type in PostgreSQL if it does not already exist
+// in its schema. The script uses a DO block to execute the SQL command conditionally.
 func (e *Enum) GetScript() string {
 	return `
 	DO $$ BEGIN
-		IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = '` + e.Name + `') THEN
-			CREATE TYPE ` + e.Name + ` AS ENUM ('` + strings.Join(e.Values, "', '") + `');
+		IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = '` + e.Name + `' AND typnamespace = ` + namespace(e.Schema) + `) THEN
+			CREATE TYPE ` + qualify(e.Schema, e.Name) + ` AS ENUM ('` + strings.Join(e.Values, "', '") + `');
 		END IF;
 	END $$;
 	`
 }
+
+// GetUpdateScript generates a SQL script that evolves an existing ENUM type towards its declaration.
+// It renames the values listed in Renames, adds the missing values keeping the declared order
+// through ALTER TYPE ... ADD VALUE BEFORE/AFTER and, when Strict, raises an exception if the type
+// has values that are no longer declared. It must run after GetScript.
+//
+// Adding values inside a transaction requires PostgreSQL 12 or later.
+func (e *Enum) GetUpdateScript() string {
+	name := qualify(e.Schema, e.Name)
+	typeID := quote(name) + "::regtype"
+
+	labels := make([]string, len(e.Values))
+	for index, value := range e.Values {
+		labels[index] = quote(value)
+	}
+
+	olds := make([]string, 0, len(e.Renames))
+	for old := range e.Renames {
+		olds = append(olds, old)
+	}
+	sort.Strings(olds)
+
+	var renames strings.Builder
+	for _, old := range olds {
+		renames.WriteString(`
+		IF EXISTS (SELECT 1 FROM pg_enum WHERE enumtypid = ` + typeID + ` AND enumlabel = ` + quote(old) + `)
+			AND NOT EXISTS (SELECT 1 FROM pg_enum WHERE enumtypid = ` + typeID + ` AND enumlabel = ` + quote(e.Renames[old]) + `) THEN
+			ALTER TYPE ` + name + ` RENAME VALUE ` + quote(old) + ` TO ` + quote(e.Renames[old]) + `;
+		END IF;`)
+	}
+
+	var strict string
+	if e.Strict {
+		strict = `
+		SELECT string_agg(enumlabel, ', ' ORDER BY enumsortorder) INTO removed FROM pg_enum
+		WHERE enumtypid = ` + typeID + ` AND enumlabel <> ALL (labels);
+		IF removed IS NOT NULL THEN
+			RAISE EXCEPTION 'enum ` + strings.ReplaceAll(name, "'", "''") + ` has values not declared in code: %', removed;
+		END IF;`
+	}
+
+	return `
+	DO $$ DECLARE
+		labels text[] := ARRAY[` + strings.Join(labels, ", ") + `]::text[];
+		anchor text;
+		removed text;
+	BEGIN` + renames.String() + `
+		FOR i IN 1 .. coalesce(array_length(labels, 1), 0) LOOP
+			CONTINUE WHEN EXISTS (SELECT 1 FROM pg_enum WHERE enumtypid = ` + typeID + ` AND enumlabel = labels[i]);
+			IF i > 1 THEN
+				EXECUTE format('ALTER TYPE ` + name + ` ADD VALUE %L AFTER %L', labels[i], labels[i - 1]);
+			ELSE
+				SELECT enumlabel INTO anchor FROM pg_enum
+				WHERE enumtypid = ` + typeID + ` AND enumlabel = ANY (labels) ORDER BY enumsortorder LIMIT 1;
+				IF anchor IS NULL THEN
+					EXECUTE format('ALTER TYPE ` + name + ` ADD VALUE %L', labels[i]);
+				ELSE
+					EXECUTE format('ALTER TYPE ` + name + ` ADD VALUE %L BEFORE %L', labels[i], anchor);
+				END IF;
+			END IF;
+		END LOOP;` + strict + `
+	END $$;
+	`
+}
+
+// Diff compares the declared values with the values of the ENUM type in the database.
+// A type that does not exist yet reports every declared value as added.
+//
+// Parameters:
+//   - tx: the database connection or transaction to query
+//
+// Returns:
+//   - EnumDiff: the declared values missing from the database and the undeclared ones
+//   - error: if the database query fails
+func (e *Enum) Diff(tx *gorm.DB) (EnumDiff, error) {
+	var labels []string
+	query := "SELECT enumlabel FROM pg_enum WHERE enumtypid = to_regtype(?) ORDER BY enumsortorder"
+	if err := tx.Raw(query, qualify(e.Schema, e.Name)).Scan(&labels).Error; err != nil {
+		return EnumDiff{}, err
+	}
+	return diffValues(e.Values, labels), nil
+}
+
+// diffValues compares declared ENUM values with the values found in the database.
+func diffValues(declared, existing []string) EnumDiff {
+	diff := EnumDiff{}
+
+	found := make(map[string]bool, len(existing))
+	for _, value := range existing {
+		found[value] = true
+	}
+
+	wanted := make(map[string]bool, len(declared))
+	for _, value := range declared {
+		wanted[value] = true
+		if !found[value] {
+			diff.Added = append(diff.Added, value)
+		}
+	}
+
+	for _, value := range existing {
+		if !wanted[value] {
+			diff.Removed = append(diff.Removed, value)
+		}
+	}
+
+	return diff
+}
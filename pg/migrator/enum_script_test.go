@@ -1,9 +1,11 @@
 package migrator
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestGetEnumScript tests the GetScript method of the Enum type.
@@ -21,7 +23,7 @@ func TestGetEnumScript(t *testing.T) {
 			},
 			expected: `
 	DO $$ BEGIN
-		IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'status') THEN
+		IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'status' AND typnamespace = current_schema()::regnamespace) THEN
 			CREATE TYPE status AS ENUM ('active', 'inactive', 'pending');
 		END IF;
 	END $$;
@@ -35,7 +37,7 @@ func TestGetEnumScript(t *testing.T) {
 			},
 			expected: `
 	DO $$ BEGIN
-		IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'level') THEN
+		IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'level' AND typnamespace = current_schema()::regnamespace) THEN
 			CREATE TYPE level AS ENUM ('low');
 		END IF;
 	END $$;
@@ -49,10 +51,25 @@ func TestGetEnumScript(t *testing.T) {
 			},
 			expected: `
 	DO $$ BEGIN
-		IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'priority') THEN
+		IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'priority' AND typnamespace = current_schema()::regnamespace) THEN
 			CREATE TYPE priority AS ENUM ('high-priority', 'medium', 'low-priority');
 		END IF;
 	END $$;
+	`,
+		},
+		{
+			name: "Enum in a schema",
+			enum: Enum{
+				Name:   "status",
+				Values: []string{"active", "inactive"},
+				Schema: "tenant",
+			},
+			expected: `
+	DO $$ BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'status' AND typnamespace = 'tenant'::regnamespace) THEN
+			CREATE TYPE tenant.status AS ENUM ('active', 'inactive');
+		END IF;
+	END $$;
 	`,
 		},
 	}
@@ -64,3 +81,127 @@ func TestGetEnumScript(t *testing.T) {
 		})
 	}
 }
+
+// TestGetEnumUpdateScript tests the GetUpdateScript method of the Enum type.
+func TestGetEnumUpdateScript(t *testing.T) {
+	tests := []struct {
+		name     string   // name of the test case
+		enum     Enum     // the Enum object to test
+		contains []string // fragments the SQL script must contain
+		excludes []string // fragments the SQL script must not contain
+	}{
+		{
+			name: "Adds missing values in order",
+			enum: Enum{
+				Name:   "status",
+				Values: []string{"active", "inactive"},
+			},
+			contains: []string{
+				"labels text[] := ARRAY['active', 'inactive']::text[];",
+				"EXECUTE format('ALTER TYPE status ADD VALUE %L AFTER %L', labels[i], labels[i - 1]);",
+				"EXECUTE format('ALTER TYPE status ADD VALUE %L BEFORE %L', labels[i], anchor);",
+				"enumtypid = 'status'::regtype",
+			},
+			excludes: []string{"RENAME VALUE", "RAISE"},
+		},
+		{
+			name: "Renames values",
+			enum: Enum{
+				Name:    "status",
+				Values:  []string{"enabled", "disabled"},
+				Renames: map[string]string{"inactive": "disabled", "active": "enabled"},
+			},
+			contains: []string{
+				"ALTER TYPE status RENAME VALUE 'active' TO 'enabled';\n\t\tEND IF;\n\t\tIF EXISTS (SELECT 1 FROM pg_enum WHERE enumtypid = 'status'::regtype AND enumlabel = 'inactive')",
+				"AND NOT EXISTS (SELECT 1 FROM pg_enum WHERE enumtypid = 'status'::regtype AND enumlabel = 'disabled') THEN",
+				"ALTER TYPE status RENAME VALUE 'inactive' TO 'disabled';",
+			},
+		},
+		{
+			name: "Strict fails on removed values",
+			enum: Enum{
+				Name:   "level",
+				Values: []string{"low"},
+				Strict: true,
+			},
+			contains: []string{"RAISE EXCEPTION 'enum level has values not declared in code: %', removed;"},
+			excludes: []string{"RAISE WARNING"},
+		},
+		{
+			name: "Enum in a schema with quoted values",
+			enum: Enum{
+				Name:   "mood",
+				Values: []string{"it's ok"},
+				Schema: "tenant",
+			},
+			contains: []string{
+				"ARRAY['it''s ok']::text[]",
+				"enumtypid = 'tenant.mood'::regtype",
+				"ALTER TYPE tenant.mood ADD VALUE %L",
+			},
+		},
+	}
+
+	for _, item := range tests {
+		t.Run(item.name, func(t *testing.T) {
+			script := item.enum.GetUpdateScript()
+			for _, fragment := range item.contains {
+				assert.Contains(t, script, fragment)
+			}
+			for _, fragment := range item.excludes {
+				assert.NotContains(t, script, fragment)
+			}
+		})
+	}
+}
+
+// TestDiffValues tests the comparison of declared and existing ENUM values.
+func TestDiffValues(t *testing.T) {
+	tests := []struct {
+		name     string   // name of the test case
+		declared []string // values declared in code
+		existing []string // values found in the database
+		expected EnumDiff // the expected difference
+	}{
+		{
+			name:     "In sync",
+			declared: []string{"a", "b"},
+			existing: []string{"a", "b"},
+			expected: EnumDiff{},
+		},
+		{
+			name:     "Missing type",
+			declared: []string{"a", "b"},
+			expected: EnumDiff{Added: []string{"a", "b"}},
+		},
+		{
+			name:     "Added and removed values",
+			declared: []string{"a", "c"},
+			existing: []string{"a", "b"},
+			expected: EnumDiff{Added: []string{"c"}, Removed: []string{"b"}},
+		},
+	}
+
+	for _, item := range tests {
+		t.Run(item.name, func(t *testing.T) {
+			assert.Equal(t, item.expected, diffValues(item.declared, item.existing))
+		})
+	}
+}
+
+// TestEnumNewMigration tests extending an enum through a new migration, which
+// leaves the checksum of the applied one untouched and adds the new value.
+func TestEnumNewMigration(t *testing.T) {
+	applied := &SchemaMigration{Code: "001", Enums: []*Enum{{Name: "status", Values: []string{"active", "inactive"}}}}
+	sum := applied.Checksum()
+
+	extended := &Enum{Name: "status", Values: []string{"active", "pending", "inactive"}}
+	migration := &SchemaMigration{Code: "002", Enums: []*Enum{extended}}
+
+	rec := newRecorder()
+	require.NoError(t, migration.Execute(dryRun(t, rec)))
+
+	assert.Equal(t, sum, applied.Checksum())
+	assert.Equal(t, []string{strings.TrimSpace(extended.GetScript()), strings.TrimSpace(extended.GetUpdateScript())}, rec.statements)
+	assert.Contains(t, rec.statements[1], "ARRAY['active', 'pending', 'inactive']::text[]")
+}
@@ -0,0 +1,39 @@
+package migrator
+
+import "errors"
+
+var (
+	// ErrIrreversible is returned when a migration selected for rollback has no down steps.
+	ErrIrreversible = errors.New("migration cannot be rolled back")
+
+	// ErrUnknownMigration is returned when a migration code is not registered in the migrator.
+	ErrUnknownMigration = errors.New("migration is not registered")
+
+	// ErrChecksumMismatch is returned when an applied migration was modified after being applied.
+	ErrChecksumMismatch = errors.New("applied migration has changed")
+
+	// ErrLockTimeout is returned when the advisory lock is not acquired within the configured timeout.
+	ErrLockTimeout = errors.New("timed out waiting for the migration lock")
+
+	// ErrInvalidSchema is returned when a schema given to WithSchema, WithSearchPath or RunSchemas is not a valid name.
+	ErrInvalidSchema = errors.New("invalid schema name")
+
+	// ErrInvalidSource is returned when SQL migration files do not follow the expected naming.
+	ErrInvalidSource = errors.New("invalid migration source")
+
+	// ErrMissingDependency is returned when a migration requires a code that is not registered.
+	ErrMissingDependency = errors.New("migration requires an unregistered migration")
+
+	// ErrDuplicateMigration is returned when two registered migrations share the same code.
+	ErrDuplicateMigration = errors.New("migration code is registered more than once")
+
+	// ErrDependencyCycle is returned when migration requirements form a cycle.
+	ErrDependencyCycle = errors.New("migration dependency cycle")
+
+	// ErrPartialMigration is returned when a migration fails after some of its steps were committed
+	// outside of a transaction; the migration is not recorded, so its steps must be reviewed before running it again.
+	ErrPartialMigration = errors.New("migration partially applied outside a transaction")
+
+	// ErrNoChanges is returned when a migration is generated for a database already in sync.
+	ErrNoChanges = errors.New("no changes to migrate")
+)
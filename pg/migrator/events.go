@@ -0,0 +1,145 @@
+package migrator
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// EventKind identifies what happened in an Event.
+type EventKind string
+
+const (
+	EventRunStarted          EventKind = "run_started"          // A run acquired the lock and started
+	EventRunFinished         EventKind = "run_finished"         // A run finished, with Err set if it failed
+	EventMigrationStarted    EventKind = "migration_started"    // A pending or outdated migration started
+	EventMigrationApplied    EventKind = "migration_applied"    // A pending migration was applied and recorded
+	EventMigrationReapplied  EventKind = "migration_reapplied"  // An outdated repeatable migration was applied again
+	EventMigrationFailed     EventKind = "migration_failed"     // A migration failed, with Err set
+	EventBatchApplied        EventKind = "batch_applied"        // A batch of a batched migration was committed, with its Cursor
+	EventMigrationRolledBack EventKind = "migration_rolledback" // An applied migration was rolled back
+)
+
+// Event describes a step of a run, delivered to the observers configured with WithObserver.
+type Event struct {
+	Kind       EventKind     // What happened
+	Schema     string        // PostgreSQL schema migrated, empty for the current schema
+	Code       string        // Code of the migration, empty for run events
+	Name       string        // Name of the migration, empty for run events
+	Duration   time.Duration // Time taken by the migration or run, zero for started events
+	Statements int           // SQL statements executed by the migration, zero for run events
+	Cursor     string        // Position reached by a batched migration, for batch events
+	Err        error         // Error of a failed migration or run
+}
+
+// Observer receives the events of the migrator, e.g. to feed logs or metrics.
+// Events are delivered synchronously, in order, while the migrator holds its lock.
+type Observer interface {
+	Observe(event Event)
+}
+
+// ObserverFunc adapts a function to the Observer interface.
+type ObserverFunc func(event Event)
+
+// Observe calls f with the event.
+func (f ObserverFunc) Observe(event Event) {
+	f(event)
+}
+
+// MigrationHook is called before or after a migration executes, with the session
+// it executes on: its transaction, unless the migration opted out of it. Returning
+// an error fails the migration, rolling back its transaction. For a batched
+// migration, the hooks run in the transactions of the first batch of the run and
+// of its last batch.
+type MigrationHook func(db *gorm.DB, migration Migration) error
+
+// hooks holds the functions called around runs and migrations.
+type hooks struct {
+	beforeRun       func() error  // Called when a run starts, after acquiring the lock
+	afterRun        func(error)   // Called when a run finishes, with its error
+	beforeMigration MigrationHook // Called before each migration executes
+	afterMigration  MigrationHook // Called after each migration executes
+}
+
+// emit delivers an event to every observer.
+func (m *migrator) emit(event Event) {
+	event.Schema = m.dbSchema
+	for _, observer := range m.observers {
+		observer.Observe(event)
+	}
+}
+
+// observe runs fn as the execution of a migration, emitting its started event
+// and then the given kind of event, or EventMigrationFailed when fn fails, with
+// its duration and the number of statements counted by fn.
+func (m *migrator) observe(kind EventKind, migration Migration, fn func(statements *int) error) error {
+	m.emit(Event{Kind: EventMigrationStarted, Code: migration.GetCode(), Name: migration.GetName()})
+
+	statements := 0
+	start := time.Now()
+	err := fn(&statements)
+
+	event := Event{
+		Kind:       kind,
+		Code:       migration.GetCode(),
+		Name:       migration.GetName(),
+		Duration:   time.Since(start),
+		Statements: statements,
+		Err:        err,
+	}
+	if err != nil {
+		event.Kind = EventMigrationFailed
+	}
+	m.emit(event)
+
+	return err
+}
+
+// execute runs the Execute step of a migration on db between the migration
+// hooks, counting its statements.
+func (m *migrator) execute(db *gorm.DB, migration Migration, statements *int) error {
+	if m.hooks.beforeMigration != nil {
+		if err := m.hooks.beforeMigration(db, migration); err != nil {
+			return err
+		}
+	}
+
+	if err := migration.Execute(counted(db, statements)); err != nil {
+		return err
+	}
+
+	if m.hooks.afterMigration != nil {
+		return m.hooks.afterMigration(db, migration)
+	}
+	return nil
+}
+
+// counted returns a session of db that counts the statements it executes.
+func counted(db *gorm.DB, statements *int) *gorm.DB {
+	return db.Session(&gorm.Session{Logger: counter{Interface: db.Logger, statements: statements}})
+}
+
+// counter is a GORM logger that counts the statements it traces, except the
+// read-only queries, and forwards everything to the logger it wraps.
+type counter struct {
+	logger.Interface
+	statements *int
+}
+
+// LogMode returns a counter wrapping the wrapped logger with the given level.
+func (c counter) LogMode(level logger.LogLevel) logger.Interface {
+	return counter{Interface: c.Interface.LogMode(level), statements: c.statements}
+}
+
+// Trace counts the statement unless it is a read-only query, and forwards it
+// to the wrapped logger.
+func (c counter) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	sql, _ := fc()
+	if statement := strings.TrimSpace(sql); statement != "" && !isQuery(statement) {
+		*c.statements++
+	}
+	c.Interface.Trace(ctx, begin, fc, err)
+}
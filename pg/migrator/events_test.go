@@ -0,0 +1,104 @@
+package migrator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestObserve tests the events emitted around the execution of a migration.
+func TestObserve(t *testing.T) {
+	var events []Event
+	m := New(nil, WithSchema("tenant"), WithObserver(ObserverFunc(func(event Event) {
+		events = append(events, event)
+	})))
+	migration := &SQLMigration{Code: "0001", Name: "Create users"}
+
+	err := m.observe(EventMigrationApplied, migration, func(statements *int) error {
+		*statements = 3
+		return nil
+	})
+	require.NoError(t, err)
+
+	failure := errors.New("syntax error")
+	err = m.observe(EventMigrationApplied, migration, func(statements *int) error {
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	require.Len(t, events, 4)
+	assert.Equal(t, Event{Kind: EventMigrationStarted, Schema: "tenant", Code: "0001", Name: "Create users"}, events[0])
+	assert.Equal(t, EventMigrationApplied, events[1].Kind)
+	assert.Equal(t, 3, events[1].Statements)
+	assert.Equal(t, "tenant", events[1].Schema)
+	assert.NoError(t, events[1].Err)
+	assert.Equal(t, EventMigrationStarted, events[2].Kind)
+	assert.Equal(t, EventMigrationFailed, events[3].Kind)
+	assert.ErrorIs(t, events[3].Err, failure)
+}
+
+// TestExecuteHooks tests that migration hooks run around Execute and that its statements are counted.
+func TestExecuteHooks(t *testing.T) {
+	var calls []string
+	hook := func(name string) MigrationHook {
+		return func(db *gorm.DB, migration Migration) error {
+			calls = append(calls, name+" "+migration.GetCode())
+			return nil
+		}
+	}
+	m := New(nil, WithBeforeMigration(hook("before")), WithAfterMigration(hook("after")))
+	migration := &SQLMigration{Code: "0001", Up: "VACUUM users;\nVACUUM roles;", NoTransaction: true}
+
+	statements := 0
+	require.NoError(t, m.execute(dryRun(t, newRecorder()), migration, &statements))
+	assert.Equal(t, []string{"before 0001", "after 0001"}, calls)
+	assert.Equal(t, 2, statements)
+
+	failure := errors.New("not allowed")
+	m = New(nil, WithBeforeMigration(func(db *gorm.DB, migration Migration) error { return failure }))
+	statements = 0
+	assert.ErrorIs(t, m.execute(dryRun(t, newRecorder()), migration, &statements), failure)
+	assert.Zero(t, statements)
+}
+
+// TestCounted tests that only the statements that are not read-only queries are counted.
+func TestCounted(t *testing.T) {
+	statements := 0
+	db := counted(dryRun(t, newRecorder()), &statements)
+
+	require.NoError(t, db.Exec("SELECT 1 FROM pg_type WHERE typname = ?", "status").Error)
+	require.NoError(t, db.Exec("VACUUM users").Error)
+	require.NoError(t, db.Exec("WITH moved AS (SELECT 1) SELECT * FROM moved").Error)
+	require.NoError(t, db.Exec("UPDATE users SET active = true").Error)
+
+	assert.Equal(t, 2, statements)
+}
+
+// TestRunHooks tests the hooks and events around a run.
+func TestRunHooks(t *testing.T) {
+	var kinds []EventKind
+	var finished error
+	m := New(dryRun(t, newRecorder()),
+		WithAfterRun(func(err error) { finished = err }),
+		WithObserver(ObserverFunc(func(event Event) { kinds = append(kinds, event.Kind) })),
+	)
+
+	err := m.run()
+	require.Error(t, err)
+	assert.Equal(t, err, finished)
+	assert.Equal(t, []EventKind{EventRunStarted, EventRunFinished}, kinds)
+
+	aborted := errors.New("maintenance window closed")
+	kinds, finished = nil, nil
+	m = New(nil,
+		WithBeforeRun(func() error { return aborted }),
+		WithAfterRun(func(err error) { finished = err }),
+		WithObserver(ObserverFunc(func(event Event) { kinds = append(kinds, event.Kind) })),
+	)
+	assert.ErrorIs(t, m.run(), aborted)
+	assert.Nil(t, finished)
+	assert.Empty(t, kinds)
+}
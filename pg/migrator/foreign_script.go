@@ -1,29 +1,97 @@
 package migrator
 
-import "fmt"
+import "strings"
+
+// Action is the referential action of a foreign key when the referenced row is deleted or updated.
+type Action string
+
+const (
+	ActionCascade    Action = "CASCADE"     // Delete or update the referencing rows too
+	ActionRestrict   Action = "RESTRICT"    // Fail immediately if referencing rows exist
+	ActionNoAction   Action = "NO ACTION"   // Fail at the end of the statement, or of the transaction when deferred
+	ActionSetNull    Action = "SET NULL"    // Set the referencing columns to NULL
+	ActionSetDefault Action = "SET DEFAULT" // Set the referencing columns to their defaults
+)
 
 // Foreign is used to avoid "import cycle not allowed" errors in Go.
 // It contains foreign key details, but the main purpose is to break circular dependencies.
+//
+// A composite foreign key is declared through Columns and ReferenceColumns instead of
+// ForeignID and ReferenceID. Both actions default to ActionCascade.
 type Foreign struct {
-	Table       string // Table containing the foreign key
-	ForeignID   string // Column in the table acting as the foreign key
-	Reference   string // Referenced table
-	ReferenceID string // Referenced column
+	Table             string   // Table containing the foreign key
+	ForeignID         string   // Column in the table acting as the foreign key
+	Reference         string   // Referenced table
+	ReferenceID       string   // Referenced column
+	Schema            string   // Optional schema of both tables, resolved through search_path when empty
+	Name              string   // Optional name of the constraint, fk_<table>_<columns> when empty
+	Columns           []string // Columns of a composite foreign key, used instead of ForeignID
+	ReferenceColumns  []string // Referenced columns of a composite foreign key, used instead of ReferenceID
+	OnDelete          Action   // Action when the referenced row is deleted, ActionCascade when empty
+	OnUpdate          Action   // Action when the referenced row is updated, ActionCascade when empty
+	Deferrable        bool     // Whether the constraint check can be deferred to the end of the transaction
+	InitiallyDeferred bool     // Whether the constraint check is deferred by default, implies Deferrable
 }
 
 // GetScript generates a SQL script for adding a foreign key constraint, but its primary purpose
 // is to break import cycles in Go by creating an indirect dependency between packages.
 func (f *Foreign) GetScript() string {
 
-	fkname := fmt.Sprintf("fk_%s_%s", f.Table, f.ForeignID)
+	columns := f.columns()
+	fkname := f.name()
+	table := qualify(f.Schema, f.Table)
 
 	return `
 	DO $$ BEGIN
-		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname= '` + fkname + `') THEN
-			ALTER TABLE public.` + f.Table + ` ADD CONSTRAINT ` + fkname + `
-			FOREIGN KEY (` + f.ForeignID + `) REFERENCES ` + f.Reference + `(` + f.ReferenceID + `) 
-			ON UPDATE CASCADE ON DELETE CASCADE;
+		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname= '` + fkname + `' AND conrelid = '` + table + `'::regclass) THEN
+			ALTER TABLE ` + table + ` ADD CONSTRAINT ` + fkname + `
+			FOREIGN KEY (` + strings.Join(columns, ", ") + `) REFERENCES ` + qualify(f.Schema, f.Reference) + `(` + strings.Join(f.references(), ", ") + `) 
+			ON UPDATE ` + string(action(f.OnUpdate)) + ` ON DELETE ` + string(action(f.OnDelete)) + f.deferral() + `;
 		END IF;
 	END $$;
 	`
 }
+
+// name returns the name of the constraint.
+func (f *Foreign) name() string {
+	if f.Name != "" {
+		return f.Name
+	}
+	return "fk_" + f.Table + "_" + strings.Join(f.columns(), "_")
+}
+
+// columns returns the referencing columns of the foreign key.
+func (f *Foreign) columns() []string {
+	if len(f.Columns) > 0 {
+		return f.Columns
+	}
+	return []string{f.ForeignID}
+}
+
+// references returns the referenced columns of the foreign key.
+func (f *Foreign) references() []string {
+	if len(f.ReferenceColumns) > 0 {
+		return f.ReferenceColumns
+	}
+	return []string{f.ReferenceID}
+}
+
+// deferral returns the DEFERRABLE clause of the constraint, if any.
+func (f *Foreign) deferral() string {
+	switch {
+	case f.InitiallyDeferred:
+		return " DEFERRABLE INITIALLY DEFERRED"
+	case f.Deferrable:
+		return " DEFERRABLE"
+	default:
+		return ""
+	}
+}
+
+// action returns the given referential action, or ActionCascade when empty.
+func action(a Action) Action {
+	if a == "" {
+		return ActionCascade
+	}
+	return a
+}
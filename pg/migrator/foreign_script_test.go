@@ -23,8 +23,8 @@ func TestGetForeignScript(t *testing.T) {
 			},
 			expected: `
 	DO $$ BEGIN
-		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname= 'fk_orders_customer_id') THEN
-			ALTER TABLE public.orders ADD CONSTRAINT fk_orders_customer_id
+		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname= 'fk_orders_customer_id' AND conrelid = 'orders'::regclass) THEN
+			ALTER TABLE orders ADD CONSTRAINT fk_orders_customer_id
 			FOREIGN KEY (customer_id) REFERENCES customers(id) 
 			ON UPDATE CASCADE ON DELETE CASCADE;
 		END IF;
@@ -41,8 +41,8 @@ func TestGetForeignScript(t *testing.T) {
 			},
 			expected: `
 	DO $$ BEGIN
-		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname= 'fk_products_category_id') THEN
-			ALTER TABLE public.products ADD CONSTRAINT fk_products_category_id
+		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname= 'fk_products_category_id' AND conrelid = 'products'::regclass) THEN
+			ALTER TABLE products ADD CONSTRAINT fk_products_category_id
 			FOREIGN KEY (category_id) REFERENCES categories(id) 
 			ON UPDATE CASCADE ON DELETE CASCADE;
 		END IF;
@@ -59,12 +59,91 @@ func TestGetForeignScript(t *testing.T) {
 			},
 			expected: `
 	DO $$ BEGIN
-		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname= 'fk_employees_department_id') THEN
-			ALTER TABLE public.employees ADD CONSTRAINT fk_employees_department_id
+		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname= 'fk_employees_department_id' AND conrelid = 'employees'::regclass) THEN
+			ALTER TABLE employees ADD CONSTRAINT fk_employees_department_id
 			FOREIGN KEY (department_id) REFERENCES departments(id) 
 			ON UPDATE CASCADE ON DELETE CASCADE;
 		END IF;
 	END $$;
+	`,
+		},
+		{
+			name: "Foreign key in a schema",
+			foreign: Foreign{
+				Table:       "orders",
+				ForeignID:   "customer_id",
+				Reference:   "customers",
+				ReferenceID: "id",
+				Schema:      "tenant",
+			},
+			expected: `
+	DO $$ BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname= 'fk_orders_customer_id' AND conrelid = 'tenant.orders'::regclass) THEN
+			ALTER TABLE tenant.orders ADD CONSTRAINT fk_orders_customer_id
+			FOREIGN KEY (customer_id) REFERENCES tenant.customers(id) 
+			ON UPDATE CASCADE ON DELETE CASCADE;
+		END IF;
+	END $$;
+	`,
+		},
+		{
+			name: "Composite foreign key with actions",
+			foreign: Foreign{
+				Table:            "order_lines",
+				Columns:          []string{"order_id", "tenant_id"},
+				Reference:        "orders",
+				ReferenceColumns: []string{"id", "tenant_id"},
+				OnDelete:         ActionRestrict,
+				OnUpdate:         ActionNoAction,
+			},
+			expected: `
+	DO $$ BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname= 'fk_order_lines_order_id_tenant_id' AND conrelid = 'order_lines'::regclass) THEN
+			ALTER TABLE order_lines ADD CONSTRAINT fk_order_lines_order_id_tenant_id
+			FOREIGN KEY (order_id, tenant_id) REFERENCES orders(id, tenant_id) 
+			ON UPDATE NO ACTION ON DELETE RESTRICT;
+		END IF;
+	END $$;
+	`,
+		},
+		{
+			name: "Named deferrable foreign key",
+			foreign: Foreign{
+				Name:        "fk_employee_manager",
+				Table:       "employees",
+				ForeignID:   "manager_id",
+				Reference:   "employees",
+				ReferenceID: "id",
+				OnDelete:    ActionSetNull,
+				Deferrable:  true,
+			},
+			expected: `
+	DO $$ BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname= 'fk_employee_manager' AND conrelid = 'employees'::regclass) THEN
+			ALTER TABLE employees ADD CONSTRAINT fk_employee_manager
+			FOREIGN KEY (manager_id) REFERENCES employees(id) 
+			ON UPDATE CASCADE ON DELETE SET NULL DEFERRABLE;
+		END IF;
+	END $$;
+	`,
+		},
+		{
+			name: "Initially deferred foreign key",
+			foreign: Foreign{
+				Table:             "orders",
+				ForeignID:         "customer_id",
+				Reference:         "customers",
+				ReferenceID:       "id",
+				InitiallyDeferred: true,
+			},
+			expected: `
+	DO $$ BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname= 'fk_orders_customer_id' AND conrelid = 'orders'::regclass) THEN
+			ALTER TABLE orders ADD CONSTRAINT fk_orders_customer_id
+			FOREIGN KEY (customer_id) REFERENCES customers(id) 
+			ON UPDATE CASCADE ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED;
+		END IF;
+	END $$;
 	`,
 		},
 	}
@@ -0,0 +1,282 @@
+package migrator
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// codeLayout is the time layout of generated migration codes, sortable and
+// 14 characters long, within the 20 characters of the tracking table.
+const codeLayout = "20060102150405"
+
+// ScaffoldFormat is the kind of file written for a generated migration.
+type ScaffoldFormat string
+
+const (
+	ScaffoldGo  ScaffoldFormat = "go"  // A Go file declaring a SchemaMigration
+	ScaffoldSQL ScaffoldFormat = "sql" // An up SQL file readable by Load
+)
+
+// Scaffold describes the migration file to generate.
+type Scaffold struct {
+	Dir     string         // Directory where the file is written
+	Name    string         // Human-readable name of the migration (max 100 chars)
+	Format  ScaffoldFormat // Kind of file to write, ScaffoldGo when empty
+	Package string         // Package of a Go file, "migrations" when empty
+	Time    time.Time      // Time used for the code, the current time when zero
+}
+
+// NewCode returns a migration code for the given time, e.g. "20250131154500".
+// Codes generated this way sort in creation order.
+func NewCode(t time.Time) string {
+	return t.UTC().Format(codeLayout)
+}
+
+// Generate compares the registered schema migrations with the database, as
+// Diff does, and writes a new migration with a timestamped code that brings
+// the database in sync.
+//
+// A Go file declares a SchemaMigration that creates the missing ENUM types
+// and values as dependencies, auto-migrates the entities whose table or
+// columns differ, and adds the missing indexes and constraints as procedures.
+// A SQL file contains the suggested statements of every difference. In both,
+// destructive changes such as dropping extra columns are left as comments
+// to review.
+//
+// Parameters:
+//   - scaffold: where and how the migration is written
+//
+// Returns:
+//   - string: the path of the written file
+//   - error: ErrNoChanges when the database is in sync, or if the catalog
+//     cannot be queried or the file cannot be written
+func (m *migrator) Generate(scaffold Scaffold) (path string, err error) {
+	err = m.connected(func() error {
+		expected, err := m.expect()
+		if err != nil {
+			return err
+		}
+		actual, err := m.introspect()
+		if err != nil {
+			return err
+		}
+		path, err = scaffold.generate(expected, actual)
+		return err
+	})
+	return path, err
+}
+
+// generate writes the migration that brings the catalog in sync with the
+// declared schema, failing with ErrNoChanges when they do not differ.
+func (s Scaffold) generate(expected expectation, actual catalog) (string, error) {
+	diff := compare(expected, actual)
+	if len(diff) == 0 {
+		return "", ErrNoChanges
+	}
+	return s.write(diff, expected.tables)
+}
+
+// GenerateEmpty writes a new migration with a timestamped code and no statements,
+// to be completed by hand.
+//
+// Parameters:
+//   - scaffold: where and how the migration is written
+//
+// Returns:
+//   - string: the path of the written file
+//   - error: if the file cannot be written
+func GenerateEmpty(scaffold Scaffold) (string, error) {
+	return scaffold.write(nil, nil)
+}
+
+// write renders the migration for the given differences and writes it to its file.
+func (s Scaffold) write(diff Diff, tables []table) (string, error) {
+	if s.Name == "" || len(s.Name) > 100 {
+		return "", fmt.Errorf("%w: name must have between 1 and 100 characters", ErrInvalidSource)
+	}
+
+	at := s.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	code := NewCode(at)
+
+	var content []byte
+	var file string
+	var err error
+	switch s.Format {
+	case ScaffoldSQL:
+		file = code + "_" + slug(s.Name) + ".up.sql"
+		content = []byte(renderSQL(code, s.Name, diff))
+	case ScaffoldGo, "":
+		file = code + "_" + slug(s.Name) + ".go"
+		content, err = renderGo(s.pkg(), code, s.Name, diff, tables)
+	default:
+		err = fmt.Errorf("%w: unknown format %q", ErrInvalidSource, s.Format)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(s.Dir, file)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// pkg returns the package of a Go file.
+func (s Scaffold) pkg() string {
+	if s.Package == "" {
+		return "migrations"
+	}
+	return s.Package
+}
+
+// slug converts a migration name to the part of a file name that Load reads back as the name.
+func slug(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "_")
+}
+
+// renderSQL renders an up SQL file with the suggested statements of the differences.
+func renderSQL(code, name string, diff Diff) string {
+	var sql strings.Builder
+	sql.WriteString("-- " + code + " " + name + "\n\n")
+	if len(diff) == 0 {
+		return sql.String()
+	}
+	sql.WriteString(diff.SQL() + "\n")
+	return sql.String()
+}
+
+// renderGo renders a Go file declaring a SchemaMigration for the differences.
+func renderGo(pkg, code, name string, diff Diff, tables []table) ([]byte, error) {
+	entities := make(map[string]interface{}, len(tables))
+	for _, t := range tables {
+		entities[t.name] = t.entity
+	}
+
+	var dependencies, procedures, notes []string
+	var changed []interface{}
+	added := map[string]bool{}
+	for _, drift := range diff {
+		switch drift.Kind {
+		case DriftEnum:
+			dependencies = append(dependencies, strings.TrimSpace(drift.SQL))
+		case DriftMissingIndex, DriftMissingConstraint:
+			procedures = append(procedures, strings.TrimSpace(drift.SQL))
+		case DriftExtraColumn:
+			notes = append(notes, strings.TrimSpace(drift.SQL))
+		default:
+			if entity := entities[drift.Table]; entity != nil && !added[drift.Table] {
+				added[drift.Table] = true
+				changed = append(changed, entity)
+			}
+		}
+	}
+
+	imports := map[string]string{"github.com/pinzlab/goutil/pg/migrator": "migrator"}
+	var literals []string
+	for _, entity := range changed {
+		typ := reflect.TypeOf(entity)
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.PkgPath() == "" || typ.PkgPath() == "main" {
+			notes = append(notes, "// Entity "+typ.String()+" cannot be imported, add it by hand")
+			continue
+		}
+		literals = append(literals, "&"+alias(imports, typ.PkgPath())+"."+typ.Name()+"{}")
+	}
+
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var src bytes.Buffer
+	src.WriteString("package " + pkg + "\n\nimport (\n")
+	for _, path := range paths {
+		if imports[path] == lastElement(path) {
+			src.WriteString(strconv.Quote(path) + "\n")
+		} else {
+			src.WriteString(imports[path] + " " + strconv.Quote(path) + "\n")
+		}
+	}
+	src.WriteString(")\n\n")
+
+	variable := "Migration" + code
+	src.WriteString("// " + variable + " is the " + strconv.Quote(name) + " migration.\n")
+	for _, note := range notes {
+		src.WriteString("//\n// " + strings.TrimPrefix(note, "// ") + "\n")
+	}
+	src.WriteString("var " + variable + " = &migrator.SchemaMigration{\n")
+	src.WriteString("Code: " + strconv.Quote(code) + ",\n")
+	src.WriteString("Name: " + strconv.Quote(name) + ",\n")
+	if len(dependencies) > 0 {
+		src.WriteString("Dependencies: []string{\n" + strings.Join(quoteAll(dependencies), ",\n") + ",\n},\n")
+	}
+	if len(literals) > 0 {
+		src.WriteString("Entities: []interface{}{\n" + strings.Join(literals, ",\n") + ",\n},\n")
+	}
+	if len(procedures) > 0 {
+		src.WriteString("Procedures: []string{\n" + strings.Join(quoteAll(procedures), ",\n") + ",\n},\n")
+	}
+	src.WriteString("}\n")
+
+	return format.Source(src.Bytes())
+}
+
+// alias registers the import of a package path and returns its name in the file,
+// adding a numeric suffix when another imported package has the same name.
+func alias(imports map[string]string, path string) string {
+	if name, ok := imports[path]; ok {
+		return name
+	}
+
+	base := strings.NewReplacer("-", "", ".", "").Replace(lastElement(path))
+	name := base
+	for suffix := 2; ; suffix++ {
+		taken := false
+		for _, other := range imports {
+			taken = taken || other == name
+		}
+		if !taken {
+			break
+		}
+		name = base + strconv.Itoa(suffix)
+	}
+
+	imports[path] = name
+	return name
+}
+
+// lastElement returns the last element of an import path.
+func lastElement(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// quoteAll quotes SQL scripts as Go string literals, raw when possible.
+func quoteAll(scripts []string) []string {
+	quoted := make([]string, len(scripts))
+	for i, script := range scripts {
+		if strings.Contains(script, "`") {
+			quoted[i] = strconv.Quote(script)
+		} else {
+			quoted[i] = "`" + script + "`"
+		}
+	}
+	return quoted
+}
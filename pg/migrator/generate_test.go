@@ -0,0 +1,126 @@
+package migrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewCode tests that generated codes are sortable and fit the tracking table.
+func TestNewCode(t *testing.T) {
+	at := time.Date(2025, 1, 31, 15, 45, 0, 0, time.UTC)
+
+	assert.Equal(t, "20250131154500", NewCode(at))
+	assert.Equal(t, "20250131154500", NewCode(at.In(time.FixedZone("UTC-5", -5*3600))))
+	assert.Less(t, NewCode(at), NewCode(at.Add(time.Second)))
+	assert.LessOrEqual(t, len(NewCode(at)), 20)
+}
+
+// TestRenderGo tests the Go file generated for a diff.
+func TestRenderGo(t *testing.T) {
+	diff := Diff{
+		{Kind: DriftEnum, Table: "role_enum", SQL: "\n\tCREATE TYPE role_enum AS ENUM ('admin');\n"},
+		{Kind: DriftMissingColumn, Table: "diff_users", Name: "email"},
+		{Kind: DriftNullable, Table: "diff_users", Name: "name"},
+		{Kind: DriftExtraColumn, Table: "diff_users", Name: "legacy", SQL: "-- ALTER TABLE diff_users DROP COLUMN legacy;"},
+		{Kind: DriftMissingIndex, Table: "diff_users", Name: "uni_users_email", SQL: "CREATE UNIQUE INDEX uni_users_email ON users(`email`);"},
+	}
+	tables := []table{{name: "diff_users", entity: &diffUser{}}}
+
+	src, err := renderGo("migrations", "20250131154500", "Add email", diff, tables)
+	require.NoError(t, err)
+
+	assert.Equal(t, `package migrations
+
+import (
+	"github.com/pinzlab/goutil/pg/migrator"
+)
+
+// Migration20250131154500 is the "Add email" migration.
+//
+// -- ALTER TABLE diff_users DROP COLUMN legacy;
+var Migration20250131154500 = &migrator.SchemaMigration{
+	Code: "20250131154500",
+	Name: "Add email",
+	Dependencies: []string{
+		`+"`CREATE TYPE role_enum AS ENUM ('admin');`"+`,
+	},
+	Entities: []interface{}{
+		&migrator.diffUser{},
+	},
+	Procedures: []string{
+		"CREATE UNIQUE INDEX uni_users_email ON users(`+"`email`"+`);",
+	},
+}
+`, string(src))
+}
+
+// TestAlias tests naming the imports of entity packages.
+func TestAlias(t *testing.T) {
+	imports := map[string]string{"github.com/pinzlab/goutil/pg/migrator": "migrator"}
+
+	assert.Equal(t, "model", alias(imports, "example.com/app/model"))
+	assert.Equal(t, "model", alias(imports, "example.com/app/model"))
+	assert.Equal(t, "model2", alias(imports, "example.com/lib/model"))
+	assert.Equal(t, "goutil", alias(imports, "example.com/go-util"))
+}
+
+// TestGenerateEmpty tests writing empty scaffolds that Load can read back.
+func TestGenerateEmpty(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2025, 1, 31, 15, 45, 0, 0, time.UTC)
+
+	path, err := GenerateEmpty(Scaffold{Dir: dir, Name: "Add Profile", Format: ScaffoldSQL, Time: at})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "20250131154500_add_profile.up.sql"), path)
+
+	migrations, err := LoadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, migrations, 1)
+	assert.Equal(t, "20250131154500", migrations[0].GetCode())
+	assert.Equal(t, "add profile", migrations[0].GetName())
+
+	path, err = GenerateEmpty(Scaffold{Dir: dir, Name: "Add Profile", Time: at})
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "package migrations")
+	assert.Contains(t, string(content), `Code: "20250131154500",`)
+
+	_, err = GenerateEmpty(Scaffold{Dir: dir})
+	assert.ErrorIs(t, err, ErrInvalidSource)
+	_, err = GenerateEmpty(Scaffold{Dir: dir, Name: "x", Format: "yaml"})
+	assert.ErrorIs(t, err, ErrInvalidSource)
+}
+
+// TestGenerateRedeclaredEnum tests that an enum redeclared with the values
+// found in the database does not generate a migration.
+func TestGenerateRedeclaredEnum(t *testing.T) {
+	dir := t.TempDir()
+	m := New(dryRun(t, newRecorder()))
+	m.AddSchema(
+		&SchemaMigration{Code: "001", Enums: []*Enum{{Name: "status", Values: []string{"active"}}}},
+		&SchemaMigration{Code: "002", Enums: []*Enum{{Name: "status", Values: []string{"active", "pending"}}}},
+	)
+
+	expected, err := m.expect()
+	require.NoError(t, err)
+
+	scaffold := Scaffold{Dir: dir, Name: "Sync"}
+	_, err = scaffold.generate(expected, catalog{enums: map[string][]string{"status": {"active", "pending"}}})
+	assert.ErrorIs(t, err, ErrNoChanges)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	path, err := scaffold.generate(expected, catalog{enums: map[string][]string{"status": {"active"}}})
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "ARRAY['active', 'pending']::text[]")
+}
@@ -0,0 +1,76 @@
+package migrator
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Index represents a regular, partial or expression index on a table, using any
+// index method supported by PostgreSQL, e.g. btree, hash, gin, gist or brin.
+//
+// A concurrent index is built without locking the table against writes. Since
+// PostgreSQL does not allow CREATE INDEX CONCURRENTLY inside a transaction, a
+// SchemaMigration creates it after its transaction commits.
+type Index struct {
+	Table        string   // Name of the table
+	Name         string   // Optional name of the index, idx_<table>_<columns> when empty
+	Columns      []string // Columns or expressions to index, e.g. "lower(email)"
+	Method       string   // Optional index method, btree when empty
+	Where        string   // Optional predicate of a partial index
+	Include      []string // Optional non-key columns stored in the index
+	Concurrently bool     // Whether to build the index without blocking writes
+	Schema       string   // Optional schema of the table, resolved through search_path when empty
+}
+
+// GetScript generates the SQL script that creates the index if it does not already exist.
+func (i *Index) GetScript() string {
+	var script strings.Builder
+	script.WriteString(`
+	CREATE INDEX `)
+	if i.Concurrently {
+		script.WriteString("CONCURRENTLY ")
+	}
+	script.WriteString("IF NOT EXISTS " + i.name() + `
+		ON ` + qualify(i.Schema, i.Table))
+	if i.Method != "" {
+		script.WriteString(" USING " + i.Method)
+	}
+	script.WriteString(" (" + strings.Join(i.Columns, ", ") + ")")
+	if len(i.Include) > 0 {
+		script.WriteString(" INCLUDE (" + strings.Join(i.Include, ", ") + ")")
+	}
+	if i.Where != "" {
+		script.WriteString(`
+	WHERE ` + i.Where)
+	}
+	script.WriteString(`;
+	`)
+	return script.String()
+}
+
+// name returns the name of the index.
+func (i *Index) name() string {
+	if i.Name != "" {
+		return i.Name
+	}
+	return identifier(append([]string{"idx", i.Table}, i.Columns...)...)
+}
+
+// repair drops the invalid index left behind by a concurrent build that failed,
+// which CREATE INDEX IF NOT EXISTS would otherwise keep as it is.
+func (i *Index) repair(db *gorm.DB) error {
+	if db.DryRun {
+		return nil
+	}
+
+	var invalid bool
+	query := "SELECT EXISTS (SELECT 1 FROM pg_index WHERE indexrelid = to_regclass(?) AND NOT indisvalid)"
+	if err := db.Raw(query, qualify(i.Schema, i.name())).Scan(&invalid).Error; err != nil {
+		return err
+	}
+	if !invalid {
+		return nil
+	}
+	return db.Exec("DROP INDEX CONCURRENTLY IF EXISTS " + qualify(i.Schema, i.name())).Error
+}
@@ -0,0 +1,89 @@
+package migrator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetIndexScript tests the GetScript method of the Index struct.
+func TestGetIndexScript(t *testing.T) {
+	tests := []struct {
+		name     string // name of the test case
+		index    Index  // the Index object to test
+		expected string // the expected SQL script
+	}{
+		{
+			name:  "Simple index",
+			index: Index{Table: "users", Columns: []string{"created_at"}},
+			expected: `
+	CREATE INDEX IF NOT EXISTS idx_users_created_at
+		ON users (created_at);
+	`,
+		},
+		{
+			name: "Partial expression index with include",
+			index: Index{
+				Table:   "users",
+				Columns: []string{"lower(email)"},
+				Include: []string{"name"},
+				Where:   "dat IS NULL",
+			},
+			expected: `
+	CREATE INDEX IF NOT EXISTS idx_users_lower_email
+		ON users (lower(email)) INCLUDE (name)
+	WHERE dat IS NULL;
+	`,
+		},
+		{
+			name: "Concurrent GIN index in a schema",
+			index: Index{
+				Table:        "documents",
+				Name:         "idx_documents_tags",
+				Columns:      []string{"tags"},
+				Method:       "gin",
+				Concurrently: true,
+				Schema:       "tenant",
+			},
+			expected: `
+	CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_documents_tags
+		ON tenant.documents USING gin (tags);
+	`,
+		},
+		{
+			name:  "BRIN index",
+			index: Index{Table: "events", Columns: []string{"occurred_at"}, Method: "brin"},
+			expected: `
+	CREATE INDEX IF NOT EXISTS idx_events_occurred_at
+		ON events USING brin (occurred_at);
+	`,
+		},
+	}
+
+	for _, item := range tests {
+		t.Run(item.name, func(t *testing.T) {
+			assert.Equal(t, item.expected, item.index.GetScript())
+		})
+	}
+}
+
+// TestDetachedIndexes tests that concurrent indexes run outside the transaction of a schema migration.
+func TestDetachedIndexes(t *testing.T) {
+	regular := &Index{Table: "users", Columns: []string{"name"}}
+	concurrent := &Index{Table: "users", Columns: []string{"email"}, Concurrently: true}
+	migration := &SchemaMigration{Code: "idx", Name: "Indexes", Indexes: []*Index{regular, concurrent}}
+
+	assert.True(t, detached(migration))
+	assert.False(t, detached(&SchemaMigration{Indexes: []*Index{regular}}))
+
+	rec := newRecorder()
+	require.NoError(t, migration.Execute(dryRun(t, rec)))
+	assert.Contains(t, rec.statements, strings.TrimSpace(regular.GetScript()))
+	assert.NotContains(t, rec.statements, strings.TrimSpace(concurrent.GetScript()))
+
+	rec = newRecorder()
+	require.NoError(t, migration.ExecuteDetached(dryRun(t, rec)))
+	assert.Equal(t, []string{strings.TrimSpace(concurrent.GetScript())}, rec.statements)
+}
@@ -47,6 +47,90 @@ func TestInsert(t *testing.T) {
 			expected: "INSERT INTO product(name, price, is_active) SELECT 'mobile', 10.5, true " +
 				"WHERE NOT EXISTS (SELECT 1 FROM product WHERE name = 'mobile');",
 		},
+		{
+			name: "Escaped value insert",
+			entity: Entity{
+				Table:   "client",
+				Check:   []string{"name"},
+				Columns: []string{"name", "tags"},
+				Values:  [][]any{{"O'Brien", []string{"vip", "new"}}},
+			},
+			expected: "INSERT INTO client(name, tags) SELECT 'O''Brien', '{\"vip\",\"new\"}' " +
+				"WHERE NOT EXISTS (SELECT 1 FROM client WHERE name = 'O''Brien');",
+		},
+		{
+			name: "Null check insert",
+			entity: Entity{
+				Table:   "product",
+				Check:   []string{"name", "brand"},
+				Columns: []string{"name", "brand"},
+				Values:  [][]any{{"mobile", nil}},
+			},
+			expected: "INSERT INTO product(name, brand) SELECT 'mobile', null " +
+				"WHERE NOT EXISTS (SELECT 1 FROM product WHERE name = 'mobile' AND brand IS NULL);",
+		},
+		{
+			name: "Upsert insert",
+			entity: Entity{
+				Table:    "country",
+				Check:    []string{"code"},
+				Columns:  []string{"code", "name"},
+				Values:   [][]any{{"EC", "Ecuador"}},
+				Conflict: ConflictUpdate,
+			},
+			expected: "INSERT INTO country(code, name) VALUES ('EC', 'Ecuador') " +
+				"ON CONFLICT (code) DO UPDATE SET name = EXCLUDED.name;",
+		},
+		{
+			name: "Upsert insert on partial index",
+			entity: Entity{
+				Table:         "country",
+				Check:         []string{"code"},
+				Columns:       []string{"code", "name", "active"},
+				Values:        [][]any{{"EC", "Ecuador", true}},
+				Conflict:      ConflictUpdate,
+				ConflictWhere: "dat IS NULL",
+			},
+			expected: "INSERT INTO country(code, name, active) VALUES ('EC', 'Ecuador', true) " +
+				"ON CONFLICT (code) WHERE dat IS NULL DO UPDATE SET name = EXCLUDED.name, active = EXCLUDED.active;",
+		},
+		{
+			name: "Upsert insert with only check columns",
+			entity: Entity{
+				Table:    "tag",
+				Check:    []string{"name"},
+				Columns:  []string{"name"},
+				Values:   [][]any{{"new"}},
+				Conflict: ConflictUpdate,
+			},
+			expected: "INSERT INTO tag(name) VALUES ('new') ON CONFLICT (name) DO NOTHING;",
+		},
+		{
+			name: "Failing insert",
+			entity: Entity{
+				Table:    "country",
+				Check:    []string{"code"},
+				Columns:  []string{"code", "name"},
+				Values:   [][]any{{"EC", "Ecuador"}},
+				Conflict: ConflictFail,
+			},
+			expected: "DO $fail$ BEGIN IF EXISTS (SELECT 1 FROM country WHERE code = 'EC') " +
+				"THEN RAISE EXCEPTION 'row already exists in %: %', 'country', 'code = ''EC'''; END IF; " +
+				"INSERT INTO country(code, name) VALUES ('EC', 'Ecuador'); END $fail$;",
+		},
+		{
+			name: "Failing insert on a table without unique constraint",
+			entity: Entity{
+				Table:    "note",
+				Check:    []string{"title", "author"},
+				Columns:  []string{"title", "author", "body"},
+				Values:   [][]any{{"todo", nil, "pay $fail$"}},
+				Conflict: ConflictFail,
+			},
+			expected: "DO $fail1$ BEGIN IF EXISTS (SELECT 1 FROM note WHERE title = 'todo' AND author IS NULL) " +
+				"THEN RAISE EXCEPTION 'row already exists in %: %', 'note', 'title = ''todo'' AND author IS NULL'; END IF; " +
+				"INSERT INTO note(title, author, body) VALUES ('todo', null, 'pay $fail$'); END $fail1$;",
+		},
 	}
 
 	for _, item := range tests {
@@ -3,20 +3,42 @@ package migrator
 import (
 	"fmt"
 	"strings"
-	"time"
+)
+
+// Conflict defines what an Entity does with rows whose Check columns
+// match an existing row.
+type Conflict int
+
+const (
+	// ConflictSkip keeps the existing row untouched (default).
+	ConflictSkip Conflict = iota
+	// ConflictUpdate updates the non-check columns of the existing row through
+	// INSERT ... ON CONFLICT DO UPDATE. It requires a unique index or constraint
+	// on the Check columns.
+	ConflictUpdate
+	// ConflictFail makes the migration fail with an exception when a row
+	// matching the Check columns already exists. It does not need any index.
+	ConflictFail
 )
 
 // Entity represents a table and a set of rows to insert,
 // skipping rows that already exist based on specified columns.
 type Entity struct {
-	Table   string   // Table name
-	Check   []string // Columns to check for existing rows
-	Columns []string // Columns to insert
-	Values  [][]any  // Rows of values to insert
+	Table         string   // Table name
+	Check         []string // Columns to check for existing rows
+	Columns       []string // Columns to insert
+	Values        [][]any  // Rows of values to insert
+	Conflict      Conflict // What to do with rows that already exist, ConflictSkip by default
+	ConflictWhere string   // Predicate of the partial unique index used by ConflictUpdate, e.g. "dat IS NULL"
 }
 
 // GetScript returns a SQL script that inserts each row only if
-// no existing row matches the Check columns.
+// no existing row matches the Check columns. Depending on Conflict,
+// existing rows are instead updated, or make the script fail.
+//
+// Values are embedded as properly quoted PostgreSQL literals (see literal),
+// so strings containing apostrophes or backslashes are safe. A nil value in
+// a Check column matches rows where that column IS NULL.
 func (e *Entity) GetScript() string {
 	var result []string
 
@@ -27,37 +49,106 @@ func (e *Entity) GetScript() string {
 	}
 
 	for _, row := range e.Values {
-		var where []string
 		var values []string
 
 		for _, value := range row {
-			switch any(value).(type) {
-			case string:
-				values = append(values, fmt.Sprintf("'%s'", value))
-			case time.Time:
-				formattedTime := (value).(time.Time).Format(time.RFC3339Nano)
-				values = append(values, fmt.Sprintf("'%s'", formattedTime))
-			case nil:
-				values = append(values, "null")
-			default:
-				values = append(values, fmt.Sprintf("%+v", value))
-			}
+			values = append(values, literal(value))
 		}
 
-		for _, check := range e.Check {
-			where = append(where, fmt.Sprintf("%s = %s", check, values[cols[check]]))
+		switch e.Conflict {
+		case ConflictUpdate:
+			result = append(result, e.upsert(values))
+		case ConflictFail:
+			result = append(result, e.insertOrFail(cols, values))
+		default:
+			result = append(result, e.insertMissing(cols, values))
 		}
+	}
+
+	return strings.Join(result, "\n")
+
+}
+
+// insert returns the plain INSERT statement of a row, without terminator.
+func (e *Entity) insert(values []string) string {
+	return fmt.Sprintf("INSERT INTO %s(%s) VALUES (%s)",
+		e.Table,
+		strings.Join(e.Columns, ", "),
+		strings.Join(values, ", "),
+	)
+}
 
-		result = append(result, fmt.Sprintf("INSERT INTO %s(%s) SELECT %s WHERE NOT EXISTS (SELECT 1 FROM %s WHERE %s);",
-			e.Table,
-			strings.Join(e.Columns, ", "),
-			strings.Join(values, ", "),
-			e.Table,
-			strings.Join(where, " AND "),
-		))
+// insertMissing returns the statement inserting a row only if no existing
+// row matches its Check columns.
+func (e *Entity) insertMissing(cols map[string]int, values []string) string {
+	return fmt.Sprintf("INSERT INTO %s(%s) SELECT %s WHERE NOT EXISTS (SELECT 1 FROM %s WHERE %s);",
+		e.Table,
+		strings.Join(e.Columns, ", "),
+		strings.Join(values, ", "),
+		e.Table,
+		e.match(cols, values),
+	)
+}
 
+// insertOrFail returns a DO block inserting a row, or raising an exception
+// when a row matching its Check columns already exists.
+func (e *Entity) insertOrFail(cols map[string]int, values []string) string {
+	where := e.match(cols, values)
+	body := fmt.Sprintf("BEGIN IF EXISTS (SELECT 1 FROM %s WHERE %s) THEN RAISE EXCEPTION 'row already exists in %%: %%', %s, %s; END IF; %s; END",
+		e.Table,
+		where,
+		quote(e.Table),
+		quote(where),
+		e.insert(values),
+	)
+
+	// Values are not escaped for dollar quoting, so the tag must not appear in them.
+	tag := "$fail$"
+	for i := 1; strings.Contains(body, tag); i++ {
+		tag = fmt.Sprintf("$fail%d$", i)
 	}
+	return fmt.Sprintf("DO %s %s %s;", tag, body, tag)
+}
 
-	return strings.Join(result, "\n")
+// match returns the condition selecting the rows whose Check columns are
+// equal to those of a row.
+func (e *Entity) match(cols map[string]int, values []string) string {
+	var where []string
 
+	for _, check := range e.Check {
+		value := values[cols[check]]
+		if value == "null" {
+			where = append(where, fmt.Sprintf("%s IS NULL", check))
+		} else {
+			where = append(where, fmt.Sprintf("%s = %s", check, value))
+		}
+	}
+
+	return strings.Join(where, " AND ")
+}
+
+// upsert returns the statement inserting a row or updating the non-check
+// columns of the row that conflicts on the Check columns.
+func (e *Entity) upsert(values []string) string {
+	target := "(" + strings.Join(e.Check, ", ") + ")"
+	if e.ConflictWhere != "" {
+		target += " WHERE " + e.ConflictWhere
+	}
+
+	checks := map[string]bool{}
+	for _, check := range e.Check {
+		checks[check] = true
+	}
+
+	var set []string
+	for _, col := range e.Columns {
+		if !checks[col] {
+			set = append(set, fmt.Sprintf("%s = EXCLUDED.%s", col, col))
+		}
+	}
+
+	if len(set) == 0 {
+		return fmt.Sprintf("%s ON CONFLICT %s DO NOTHING;", e.insert(values), target)
+	}
+	return fmt.Sprintf("%s ON CONFLICT %s DO UPDATE SET %s;", e.insert(values), target, strings.Join(set, ", "))
 }
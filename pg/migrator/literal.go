@@ -0,0 +1,156 @@
+package migrator
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// quote returns s as a PostgreSQL string literal, doubling single quotes.
+// Backslashes are kept as is, since standard_conforming_strings is on.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// literal returns the PostgreSQL literal for a Go value, so it can be safely
+// embedded in a generated script.
+//
+// Supported values:
+//   - nil and nil pointers as null; other pointers are dereferenced
+//   - strings and string-kinded types (e.g. enums) as quoted strings
+//   - integers, floats and booleans, including named types, as is
+//   - time.Time as a quoted RFC 3339 timestamp
+//   - []byte as a bytea hex literal, and [16]byte arrays (UUIDs) as a quoted UUID
+//   - json.RawMessage, maps and structs as quoted JSON
+//   - driver.Valuer (e.g. sql.NullString) through the value it returns
+//   - fmt.Stringer (e.g. uuid.UUID) through its string
+//   - other slices and arrays as quoted PostgreSQL array literals
+func literal(value any) string {
+	if value == nil {
+		return "null"
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "null"
+		}
+		return literal(rv.Elem().Interface())
+	}
+
+	switch v := value.(type) {
+	case string:
+		return quote(v)
+	case json.RawMessage:
+		return quote(string(v))
+	case []byte:
+		return quote(`\x` + hex.EncodeToString(v))
+	case time.Time:
+		return quote(v.Format(time.RFC3339Nano))
+	case driver.Valuer:
+		val, err := v.Value()
+		if err != nil {
+			return quote(fmt.Sprint(v))
+		}
+		return literal(val)
+	case fmt.Stringer:
+		return quote(v.String())
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		return quote(rv.String())
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return float(rv.Float())
+	case reflect.Array:
+		if rv.Len() == 16 && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return quote(uuid(rv))
+		}
+		return quote(array(rv))
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return quote(`\x` + hex.EncodeToString(rv.Bytes()))
+		}
+		return quote(array(rv))
+	case reflect.Map, reflect.Struct:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return quote(fmt.Sprint(value))
+		}
+		return quote(string(data))
+	}
+
+	return quote(fmt.Sprint(value))
+}
+
+// float formats a float as a PostgreSQL numeric literal, quoting the special values.
+func float(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return quote("NaN")
+	case math.IsInf(f, 1):
+		return quote("Infinity")
+	case math.IsInf(f, -1):
+		return quote("-Infinity")
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// uuid formats a 16 byte array in the canonical UUID form.
+func uuid(rv reflect.Value) string {
+	b := make([]byte, 16)
+	for i := range b {
+		b[i] = byte(rv.Index(i).Uint())
+	}
+	s := hex.EncodeToString(b)
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// array formats a slice or array as the text of a PostgreSQL array literal,
+// e.g. {"a","b"} or {{1,2},{3,4}}.
+func array(rv reflect.Value) string {
+	elements := make([]string, rv.Len())
+	for i := range elements {
+		elements[i] = element(rv.Index(i))
+	}
+	return "{" + strings.Join(elements, ",") + "}"
+}
+
+// element formats a single element of a PostgreSQL array literal.
+func element(rv reflect.Value) string {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return "NULL"
+		}
+		rv = rv.Elem()
+	}
+
+	if (rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8) ||
+		(rv.Kind() == reflect.Array && !(rv.Len() == 16 && rv.Type().Elem().Kind() == reflect.Uint8)) {
+		return array(rv)
+	}
+
+	text := literal(rv.Interface())
+	if text == "null" {
+		return "NULL"
+	}
+	if !strings.HasPrefix(text, "'") {
+		return text
+	}
+
+	text = strings.ReplaceAll(text[1:len(text)-1], "''", "'")
+	text = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text)
+	return `"` + text + `"`
+}
@@ -0,0 +1,55 @@
+package migrator
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/pinzlab/goutil/internal/helper"
+	"github.com/stretchr/testify/assert"
+)
+
+type level string
+
+type code [16]byte
+
+// TestLiteral tests the conversion of Go values into PostgreSQL literals.
+func TestLiteral(t *testing.T) {
+	tests := []struct {
+		name     string // name of the test case
+		value    any    // the value to convert
+		expected string // the expected literal
+	}{
+		{name: "Nil", value: nil, expected: "null"},
+		{name: "String", value: "mobile", expected: "'mobile'"},
+		{name: "String with apostrophe", value: "O'Brien", expected: "'O''Brien'"},
+		{name: "String with backslash", value: `C:\temp`, expected: `'C:\temp'`},
+		{name: "String kinded type", value: level("high"), expected: "'high'"},
+		{name: "Integer", value: 42, expected: "42"},
+		{name: "Unsigned integer", value: uint8(7), expected: "7"},
+		{name: "Float", value: 10.5, expected: "10.5"},
+		{name: "Not a number", value: math.NaN(), expected: "'NaN'"},
+		{name: "Boolean", value: false, expected: "false"},
+		{name: "Pointer", value: helper.Pointer("value"), expected: "'value'"},
+		{name: "Nil pointer", value: (*string)(nil), expected: "null"},
+		{name: "Time", value: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), expected: "'2025-01-02T03:04:05Z'"},
+		{name: "Bytes", value: []byte{0xde, 0xad}, expected: `'\xdead'`},
+		{name: "JSON", value: json.RawMessage(`{"name":"it's"}`), expected: `'{"name":"it''s"}'`},
+		{name: "Map", value: map[string]int{"a": 1}, expected: `'{"a":1}'`},
+		{name: "Valid null string", value: sql.NullString{String: "x", Valid: true}, expected: "'x'"},
+		{name: "Invalid null string", value: sql.NullString{}, expected: "null"},
+		{name: "UUID array", value: code{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}, expected: "'123e4567-e89b-12d3-a456-426614174000'"},
+		{name: "String array", value: []string{"a", `b"c`, "it's"}, expected: `'{"a","b\"c","it''s"}'`},
+		{name: "Integer array", value: []int{1, 2, 3}, expected: "'{1,2,3}'"},
+		{name: "Nested array", value: [][]int{{1, 2}, {3, 4}}, expected: "'{{1,2},{3,4}}'"},
+		{name: "Array with null", value: []*string{helper.Pointer("a"), nil}, expected: `'{"a",NULL}'`},
+	}
+
+	for _, item := range tests {
+		t.Run(item.name, func(t *testing.T) {
+			assert.Equal(t, item.expected, literal(item.value))
+		})
+	}
+}
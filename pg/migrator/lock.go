@@ -0,0 +1,106 @@
+package migrator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pinzlab/goutil/terminal"
+)
+
+// DefaultLockKey is the PostgreSQL advisory lock key taken by the migrator
+// when no other key is configured with WithLockKey.
+const DefaultLockKey int64 = 7283504116
+
+// lockPoll is the interval between attempts to acquire the advisory lock
+// when a lock timeout is configured.
+const lockPoll = 500 * time.Millisecond
+
+// lock holds the advisory lock settings of the migrator.
+type lock struct {
+	disabled bool          // Whether runs are executed without taking the lock
+	key      int64         // Advisory lock key shared by every instance
+	timeout  time.Duration // Maximum time to wait for the lock, zero waits forever
+	skip     bool          // Whether to skip the run instead of waiting when the lock is taken
+}
+
+// locked runs fn while holding the PostgreSQL advisory lock of the migrator,
+// so that only one instance migrates the database at a time.
+//
+// Session-level advisory locks belong to a connection, so fn runs on the
+// dedicated connection provided by connected. The target schema, if any, is
+// created once the lock is held. The lock is released when fn returns an
+// error or panics.
+//
+// Parameters:
+//   - fn: the operation to run while holding the lock
+//
+// Returns:
+//   - error: if the lock cannot be acquired in time or fn fails
+func (m *migrator) locked(fn func() error) error {
+	if m.lock.disabled {
+		return m.connected(func() error {
+			if err := m.createSchema(); err != nil {
+				return err
+			}
+			return fn()
+		})
+	}
+
+	return m.connected(func() (err error) {
+		acquired, err := m.acquire()
+		if err != nil {
+			return err
+		}
+
+		if !acquired {
+			terminal.Warning("Migrations are running in another instance, skipping")
+			return nil
+		}
+
+		defer func() {
+			unlockErr := m.db.Exec("SELECT pg_advisory_unlock(?)", m.lock.key).Error
+			if err == nil {
+				err = unlockErr
+			}
+		}()
+
+		if err := m.createSchema(); err != nil {
+			return err
+		}
+		return fn()
+	})
+}
+
+// acquire takes the advisory lock on the migrator's dedicated connection.
+//
+// In skip mode a single attempt is made. Otherwise the call blocks until the
+// lock is free or, when a timeout is configured, until it expires.
+//
+// Returns:
+//   - bool: true if the lock was acquired, false if it was skipped
+//   - error: if the query fails or the timeout expires
+func (m *migrator) acquire() (bool, error) {
+	if !m.lock.skip && m.lock.timeout == 0 {
+		err := m.db.Exec("SELECT pg_advisory_lock(?)", m.lock.key).Error
+		return err == nil, err
+	}
+
+	deadline := time.Now().Add(m.lock.timeout)
+	for {
+		acquired := false
+		err := m.db.Raw("SELECT pg_try_advisory_lock(?)", m.lock.key).Scan(&acquired).Error
+		if err != nil {
+			return false, err
+		}
+
+		if acquired || m.lock.skip {
+			return acquired, nil
+		}
+
+		if time.Now().After(deadline) {
+			return false, fmt.Errorf("%w: key %d after %s", ErrLockTimeout, m.lock.key, m.lock.timeout)
+		}
+
+		time.Sleep(lockPoll)
+	}
+}
@@ -1,6 +1,16 @@
 package migrator
 
-import "gorm.io/gorm"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Migration defines the interface that all migration types must implement
 type Migration interface {
@@ -8,3 +18,163 @@ type Migration interface {
 	GetName() string
 	Execute(tx *gorm.DB) error
 }
+
+// Reversible is implemented by migrations that can undo the changes applied by Execute.
+// The migrator only rolls back migrations that implement it and report CanRollback.
+type Reversible interface {
+	Migration
+	CanRollback() bool
+	Rollback(tx *gorm.DB) error
+}
+
+// Verifiable is implemented by migrations that can compute a checksum of the SQL
+// they generate. The migrator stores it when the migration is applied and compares
+// it on later runs to detect migrations edited after being applied.
+type Verifiable interface {
+	Migration
+	Checksum() string
+}
+
+// Repeatable is implemented by migrations that can be applied again whenever
+// their checksum changes, such as reference data kept in sync from code.
+// When IsRepeatable reports true, a changed checksum re-applies the migration
+// instead of being reported as drift.
+type Repeatable interface {
+	Verifiable
+	IsRepeatable() bool
+}
+
+// Detached is implemented by migrations with steps that cannot run inside a
+// transaction, such as CREATE INDEX CONCURRENTLY. When HasDetached reports true,
+// the migrator commits Execute first, then runs ExecuteDetached outside of any
+// transaction, and records the migration only once both succeed. Detached steps
+// must be idempotent, since a failure between them runs the migration again.
+type Detached interface {
+	Migration
+	HasDetached() bool
+	ExecuteDetached(db *gorm.DB) error
+}
+
+// Transactional is implemented by migrations that can opt out of the transaction
+// the migrator wraps around Execute. When InTransaction reports false, Execute
+// runs directly on the connection, so each statement commits on its own, as
+// required by statements such as VACUUM or CREATE INDEX CONCURRENTLY.
+type Transactional interface {
+	Migration
+	InTransaction() bool
+}
+
+// Batched is implemented by migrations that process data in batches, each one
+// committed on its own. Instead of Execute, the migrator calls ExecuteBatch with
+// the cursor returned by the previous batch, starting from the empty string,
+// and stores the new cursor in the tracking table in the same transaction, so
+// an interrupted run resumes where it stopped. Throttle is the time to wait
+// between batches.
+type Batched interface {
+	Migration
+	ExecuteBatch(tx *gorm.DB, cursor string) (next string, done bool, err error)
+	Throttle() time.Duration
+}
+
+// describer is implemented by migrations that provide a detailed description
+// to be stored in the tracking table.
+type describer interface {
+	GetDescription() string
+}
+
+// repeatable reports whether the migration is applied again when it changes.
+func repeatable(migration Migration) bool {
+	r, ok := migration.(Repeatable)
+	return ok && r.IsRepeatable()
+}
+
+// detached reports whether the migration has steps that run outside of a transaction.
+func detached(migration Migration) bool {
+	d, ok := migration.(Detached)
+	return ok && d.HasDetached()
+}
+
+// transactional reports whether the migration runs Execute inside a transaction.
+func transactional(migration Migration) bool {
+	t, ok := migration.(Transactional)
+	return !ok || t.InTransaction()
+}
+
+// checksum returns the hex encoded SHA-256 hash of the given scripts.
+func checksum(scripts ...string) string {
+	hash := sha256.New()
+	for _, script := range scripts {
+		hash.Write([]byte(script))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// declared returns a stable description of the inputs of a script builder such
+// as Enum or Unique: the name and value of each of its non-zero fields. Checksums
+// are computed over it rather than over the generated SQL, so a library upgrade
+// that renders the same declaration differently, or adds new optional fields,
+// does not change the checksum of applied migrations.
+func declared(builder any) string {
+	value := reflect.Indirect(reflect.ValueOf(builder))
+	if value.Kind() != reflect.Struct {
+		return describe(value)
+	}
+
+	parts := []string{value.Type().Name()}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() || value.Field(i).IsZero() {
+			continue
+		}
+		parts = append(parts, field.Name+"="+describe(value.Field(i)))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// describe formats a declared value, following pointers and sorting map keys
+// so the result depends only on the values themselves.
+func describe(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.Invalid:
+		return "nil"
+
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return "nil"
+		}
+		return describe(value.Elem())
+
+	case reflect.Slice, reflect.Array:
+		items := make([]string, value.Len())
+		for i := range items {
+			items[i] = describe(value.Index(i))
+		}
+		return "[" + strings.Join(items, ",") + "]"
+
+	case reflect.Map:
+		entries := make([]string, 0, value.Len())
+		for _, key := range value.MapKeys() {
+			entries = append(entries, describe(key)+":"+describe(value.MapIndex(key)))
+		}
+		sort.Strings(entries)
+		return "{" + strings.Join(entries, ",") + "}"
+	}
+
+	return fmt.Sprintf("%#v", value.Interface())
+}
+
+// rollback executes the down steps of a migration in order.
+// It fails with ErrIrreversible when the migration does not define any.
+func rollback(tx *gorm.DB, code string, steps []string) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("%w: %s", ErrIrreversible, code)
+	}
+
+	for _, step := range steps {
+		if err := tx.Exec(step).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package migrator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCanRollback tests that migrations are reversible only when they define down steps.
+func TestCanRollback(t *testing.T) {
+	tests := []struct {
+		name      string     // name of the test case
+		migration Reversible // the migration to check
+		expected  bool       // whether the migration can be rolled back
+	}{
+		{
+			name:      "Schema migration with down steps",
+			migration: &SchemaMigration{Code: "users", Down: []string{"DROP TABLE users"}},
+			expected:  true,
+		},
+		{
+			name:      "Schema migration without down steps",
+			migration: &SchemaMigration{Code: "users"},
+			expected:  false,
+		},
+		{
+			name:      "Data migration with down steps",
+			migration: &DataMigration{Code: "roles", Down: []string{"DELETE FROM roles"}},
+			expected:  true,
+		},
+		{
+			name:      "Data migration without down steps",
+			migration: &DataMigration{Code: "roles"},
+			expected:  false,
+		},
+	}
+
+	for _, item := range tests {
+		t.Run(item.name, func(t *testing.T) {
+			assert.Equal(t, item.expected, item.migration.CanRollback())
+		})
+	}
+}
+
+// TestRollbackWithoutSteps tests that a migration without down steps reports ErrIrreversible.
+func TestRollbackWithoutSteps(t *testing.T) {
+	err := (&SchemaMigration{Code: "users"}).Rollback(nil)
+	assert.ErrorIs(t, err, ErrIrreversible)
+	assert.Contains(t, err.Error(), "users")
+}
+
+// TestChecksum tests that checksums change only when the generated SQL changes.
+func TestChecksum(t *testing.T) {
+	base := &SchemaMigration{
+		Code:         "users",
+		Dependencies: []string{"CREATE EXTENSION IF NOT EXISTS unaccent"},
+		Enums:        []*Enum{{Name: "role", Values: []string{"admin", "guest"}}},
+	}
+	same := &SchemaMigration{
+		Code:         "users",
+		Name:         "Renamed migration",
+		Dependencies: []string{"CREATE EXTENSION IF NOT EXISTS unaccent"},
+		Enums:        []*Enum{{Name: "role", Values: []string{"admin", "guest"}}},
+		Down:         []string{"DROP TYPE role"},
+	}
+	changed := &SchemaMigration{
+		Code:         "users",
+		Dependencies: []string{"CREATE EXTENSION IF NOT EXISTS unaccent"},
+		Enums:        []*Enum{{Name: "role", Values: []string{"admin", "guest", "owner"}}},
+	}
+
+	assert.Len(t, base.Checksum(), 64)
+	assert.Equal(t, base.Checksum(), same.Checksum())
+	assert.NotEqual(t, base.Checksum(), changed.Checksum())
+
+	data := &DataMigration{Code: "roles", Data: []*Entity{{
+		Table:   "role",
+		Check:   []string{"name"},
+		Columns: []string{"name"},
+		Values:  [][]any{{"admin"}},
+	}}}
+	edited := &DataMigration{Code: "roles", Data: []*Entity{{
+		Table:   "role",
+		Check:   []string{"name"},
+		Columns: []string{"name"},
+		Values:  [][]any{{"owner"}},
+	}}}
+
+	assert.NotEqual(t, data.Checksum(), edited.Checksum())
+}
+
+// TestNewTracker tests that the tracking record includes the description and checksum.
+func TestNewTracker(t *testing.T) {
+	migration := &DataMigration{Code: "roles", Name: "Roles", Description: "Seed roles"}
+	record := newTracker(migration)
+
+	assert.Equal(t, "roles", record.Code)
+	assert.Equal(t, "Roles", record.Name)
+	assert.Equal(t, "Seed roles", record.Description)
+	assert.Equal(t, migration.Checksum(), record.Checksum)
+}
+
+// TestRepeatable tests that only data migrations flagged as repeatable are applied again.
+func TestRepeatable(t *testing.T) {
+	assert.True(t, repeatable(&DataMigration{Code: "countries", Repeatable: true}))
+	assert.False(t, repeatable(&DataMigration{Code: "countries"}))
+	assert.False(t, repeatable(&SchemaMigration{Code: "users"}))
+}
+
+// TestTransactional tests which migrations run inside a transaction.
+func TestTransactional(t *testing.T) {
+	assert.True(t, transactional(&DataMigration{Code: "data"}))
+	assert.True(t, transactional(&SchemaMigration{Code: "schema"}))
+	assert.False(t, transactional(&SchemaMigration{Code: "schema", NoTransaction: true}))
+	assert.False(t, transactional(&SQLMigration{Code: "sql", NoTransaction: true}))
+
+	assert.False(t, detached(&SchemaMigration{Code: "schema"}))
+	assert.True(t, detached(&SchemaMigration{Code: "schema", Outside: []string{"VACUUM users"}}))
+}
+
+// TestPartial tests the error reported when a migration fails outside a transaction.
+func TestPartial(t *testing.T) {
+	cause := errors.New("deadlock detected")
+	err := partial(&SQLMigration{Code: "0001"}, cause)
+
+	assert.ErrorIs(t, err, ErrPartialMigration)
+	assert.ErrorIs(t, err, cause)
+	assert.Contains(t, err.Error(), "0001")
+}
+
+// TestDeclared tests that checksums describe the declared inputs, independently of pointers and map order.
+func TestDeclared(t *testing.T) {
+	first, second := "admin", "admin"
+	tests := []struct {
+		name     string // Test case name
+		builder  any    // Declared script builder
+		expected string // Expected description
+	}{
+		{
+			name:     "Zero fields are left out",
+			builder:  &Check{Table: "product", Expression: "price >= 0"},
+			expected: "Check\nTable=\"product\"\nExpression=\"price >= 0\"",
+		},
+		{
+			name:     "Maps are sorted",
+			builder:  &Enum{Name: "role", Renames: map[string]string{"b": "c", "a": "d"}},
+			expected: "Enum\nName=\"role\"\nRenames={\"a\":\"d\",\"b\":\"c\"}",
+		},
+		{
+			name:     "Pointers are followed",
+			builder:  &Entity{Table: "role", Values: [][]any{{&first, 1, nil}}},
+			expected: "Entity\nTable=\"role\"\nValues=[[\"admin\",1,nil]]",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, declared(test.builder))
+		})
+	}
+
+	assert.Equal(t,
+		declared(&Entity{Table: "role", Values: [][]any{{&first}}}),
+		declared(&Entity{Table: "role", Values: [][]any{{&second}}}),
+	)
+}
@@ -1,6 +1,10 @@
 package migrator
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/pinzlab/goutil/terminal"
 	"gorm.io/gorm"
 )
@@ -9,46 +13,71 @@ import (
 // in a PostgreSQL database using GORM. It ensures that each migration
 // is applied exactly once by recording them in a tracking table.
 type migrator struct {
-	db     *gorm.DB    // Database connection
-	schema []Migration // List of migrations to apply
+	db         *gorm.DB    // Database connection
+	schema     []Migration // List of migrations to apply
+	validation Validation  // How checksum mismatches of applied migrations are handled
+	lock       lock        // Advisory lock settings for runs
+	dbSchema   string      // PostgreSQL schema migrated, empty for the current schema
+	table      string      // Name of the tracking table
+	searchPath []string    // Schemas set as search_path while migrating
+	hooks      hooks       // Functions called around runs and migrations
+	observers  []Observer  // Receivers of the run and migration events
 }
 
 // New creates a new migrator instance with the given database connection
-// and a variadic list of migration definitions.
+// and a variadic list of options.
 //
 // Parameters:
 //   - db: a pointer to the gorm.DB instance (PostgreSQL GORM wrapper)
+//   - opts: optional settings such as WithValidation or WithLockKey
 //
 // Returns:
 //   - *migrator: a configured migrator ready to run migrations
-func New(db *gorm.DB) *migrator {
-	return &migrator{
-		db: db,
+func New(db *gorm.DB, opts ...Option) *migrator {
+	m := &migrator{
+		db:    db,
+		lock:  lock{key: DefaultLockKey},
+		table: (&tracker{}).TableName(),
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
+	return m
+}
+
+// trackerTable returns the name of the tracking table, qualified with the
+// target schema when one is configured.
+func (m *migrator) trackerTable() string {
+	return qualify(m.dbSchema, m.table)
+}
+
+// track returns a query on the tracking table for the given connection or transaction.
+func (m *migrator) track(db *gorm.DB) *gorm.DB {
+	return db.Table(m.trackerTable())
 }
 
 // trackerExists checks whether the internal 'migrations' tracking table
-// exists in the connected PostgreSQL database.
+// exists in the target schema, or in the current schema when none is set.
 //
 // Returns:
 //   - bool: true if the table exists, false otherwise
 //   - error: if the query fails
 func (m *migrator) trackerExists() (bool, error) {
 	exists := false
-	tableName := (&tracker{}).TableName()
 
 	query := `
         SELECT EXISTS (
             SELECT FROM information_schema.tables 
-            WHERE table_schema = 'public' AND table_name = ?
+            WHERE table_schema = COALESCE(NULLIF(?, ''), current_schema()) AND table_name = ?
         );
     `
-	err := m.db.Raw(query, tableName).Scan(&exists).Error
+	err := m.db.Raw(query, m.dbSchema, m.table).Scan(&exists).Error
 	return exists, err
 }
 
-// migrateTracker ensures that the 'migrations' tracking table exists.
-// If it does not, the function creates it using GORM's AutoMigrate.
+// migrateTracker ensures that the 'migrations' tracking table exists and
+// has every column the migrator needs, using GORM's AutoMigrate. Tables
+// created by earlier versions of the migrator gain the new columns.
 //
 // Returns:
 //   - error: if the migration operation fails
@@ -60,15 +89,13 @@ func (m *migrator) migrateTracker() error {
 
 	if !te {
 		terminal.Info("Migrate Tracker")
-		if err := m.db.AutoMigrate(&tracker{}); err != nil {
-			return err
-		}
 	}
-	return nil
+	return m.track(m.db).AutoMigrate(&tracker{})
 }
 
 // checkMigration determines whether a migration with the given code
-// has already been applied, by checking the tracking table.
+// has already been applied, by checking the tracking table. A batched
+// migration still in progress is not applied yet.
 //
 // Parameters:
 //   - code: the unique migration code to check
@@ -77,12 +104,297 @@ func (m *migrator) migrateTracker() error {
 //   - bool: true if the migration has already been run
 //   - error: if the database query fails
 func (m *migrator) checkMigration(code string) (bool, error) {
-	var count int64
-	err := m.db.Model(&tracker{}).Where("code = ?", code).Count(&count).Error
+	var records []tracker
+	err := m.track(m.db).Where("code = ?", code).Find(&records).Error
 	if err != nil {
 		return false, err
 	}
-	return count > 0, nil
+	return len(records) > 0 && records[0].done(), nil
+}
+
+// apply executes a pending migration inside a database transaction and
+// records it in the tracking table, together with its checksum.
+//
+// Parameters:
+//   - migration: the migration to apply
+//   - statements: counter of the statements executed by the migration
+//
+// Returns:
+//   - error: if the migration or its record fails
+func (m *migrator) apply(migration Migration, statements *int) error {
+	if batched, ok := migration.(Batched); ok {
+		return m.applyBatches(batched, statements)
+	}
+	if detached(migration) || !transactional(migration) {
+		return m.applySteps(migration, statements)
+	}
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := m.execute(tx, migration, statements); err != nil {
+			return err
+		}
+		return m.track(tx).Create(newTracker(migration)).Error
+	})
+}
+
+// applySteps executes a migration with steps that cannot run inside a
+// transaction. Execute runs in its own transaction, or directly on the
+// connection when the migration opts out of it, then the detached steps run
+// on the connection, and the migration is recorded only after all of them
+// succeed, so a failed run executes it again on the next one.
+//
+// A failure after some statements were committed is reported wrapping
+// ErrPartialMigration, with guidance to review them before running again.
+//
+// Parameters:
+//   - migration: the migration to apply
+//   - statements: counter of the statements executed by the migration
+//
+// Returns:
+//   - error: if any step or the record fails
+func (m *migrator) applySteps(migration Migration, statements *int) error {
+	if transactional(migration) {
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			return m.execute(tx, migration, statements)
+		})
+		if err != nil {
+			return err
+		}
+	} else if err := m.execute(m.db, migration, statements); err != nil {
+		return partial(migration, err)
+	}
+
+	if detached(migration) {
+		if err := migration.(Detached).ExecuteDetached(counted(m.db, statements)); err != nil {
+			return partial(migration, err)
+		}
+	}
+
+	if err := m.track(m.db).Create(newTracker(migration)).Error; err != nil {
+		return partial(migration, err)
+	}
+	return nil
+}
+
+// applyBatches executes a batched migration one batch at a time, each in its
+// own transaction together with the update of its cursor in the tracking table.
+// The migration is recorded with a cursor before the first batch, resuming from
+// the stored cursor when an earlier run was interrupted, and the cursor is
+// cleared after the last batch, which marks the migration as applied. The
+// migration hooks run in the transactions of the first batch of the run and
+// of the last batch, before its cursor is cleared.
+//
+// Parameters:
+//   - migration: the batched migration to apply
+//   - statements: counter of the statements executed by the migration
+//
+// Returns:
+//   - error: if a batch or its record fails; committed batches are kept
+func (m *migrator) applyBatches(migration Batched, statements *int) error {
+	cursor, err := m.cursor(migration)
+	if err != nil {
+		return err
+	}
+
+	for first := true; ; first = false {
+		start := time.Now()
+		before := *statements
+		var next string
+		var done bool
+
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if first && m.hooks.beforeMigration != nil {
+				if err := m.hooks.beforeMigration(tx, migration); err != nil {
+					return err
+				}
+			}
+
+			var err error
+			next, done, err = migration.ExecuteBatch(counted(tx, statements), cursor)
+			if err != nil {
+				return err
+			}
+
+			if done && m.hooks.afterMigration != nil {
+				if err := m.hooks.afterMigration(tx, migration); err != nil {
+					return err
+				}
+			}
+
+			record := m.track(tx).Where("code = ?", migration.GetCode())
+			if done {
+				return record.Updates(map[string]any{"cursor": gorm.Expr("NULL"), "cat": time.Now()}).Error
+			}
+			return record.Update("cursor", next).Error
+		})
+		if err != nil {
+			terminal.Warning("Migration " + migration.GetCode() + " stopped at cursor \"" + cursor + "\", the next run resumes from it")
+			return err
+		}
+
+		m.emit(Event{
+			Kind:       EventBatchApplied,
+			Code:       migration.GetCode(),
+			Name:       migration.GetName(),
+			Duration:   time.Since(start),
+			Statements: *statements - before,
+			Cursor:     next,
+		})
+
+		if done {
+			break
+		}
+		cursor = next
+		if pause := migration.Throttle(); pause > 0 {
+			time.Sleep(pause)
+		}
+	}
+
+	return nil
+}
+
+// cursor returns the position reached by a batched migration in an earlier
+// run, recording the migration as in progress when it has not started yet.
+func (m *migrator) cursor(migration Batched) (string, error) {
+	var records []tracker
+	if err := m.track(m.db).Where("code = ?", migration.GetCode()).Find(&records).Error; err != nil {
+		return "", err
+	}
+	if len(records) > 0 && records[0].Cursor != nil {
+		terminal.About("Resume", migration.GetName()+" from cursor \""+*records[0].Cursor+"\"")
+		return *records[0].Cursor, nil
+	}
+
+	start := ""
+	record := newTracker(migration)
+	record.Cursor = &start
+	return start, m.track(m.db).Create(record).Error
+}
+
+// partial reports a migration that failed after some of its steps may have been
+// committed, explaining how to recover before running the migrations again.
+func partial(migration Migration, err error) error {
+	code := migration.GetCode()
+	terminal.Warning("Migration " + code + " failed outside a transaction and was not recorded. " +
+		"Review which of its statements were committed, revert them or make them idempotent, then run the migrations again")
+	return fmt.Errorf("%w: %s: %w", ErrPartialMigration, code, err)
+}
+
+// outdated reports whether an applied repeatable migration has changed since
+// it was last applied.
+//
+// Parameters:
+//   - migration: the applied migration to check
+//
+// Returns:
+//   - bool: true if the migration is repeatable and its checksum changed
+//   - error: if the database query fails
+func (m *migrator) outdated(migration Migration) (bool, error) {
+	if !repeatable(migration) {
+		return false, nil
+	}
+
+	var sums []string
+	err := m.track(m.db).Where("code = ?", migration.GetCode()).Pluck("checksum", &sums).Error
+	if err != nil {
+		return false, err
+	}
+	return len(sums) > 0 && sums[0] != migration.(Repeatable).Checksum(), nil
+}
+
+// reapply executes an outdated repeatable migration again inside a database
+// transaction and refreshes its checksum and timestamp in the tracking table.
+//
+// Parameters:
+//   - migration: the repeatable migration to apply again
+//   - statements: counter of the statements executed by the migration
+//
+// Returns:
+//   - error: if the migration or its record fails
+func (m *migrator) reapply(migration Repeatable, statements *int) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := m.execute(tx, migration, statements); err != nil {
+			return err
+		}
+		return m.track(tx).Where("code = ?", migration.GetCode()).Updates(map[string]any{
+			"checksum": migration.Checksum(),
+			"cat":      time.Now(),
+		}).Error
+	})
+}
+
+// Validate compares the checksum of every applied migration that implements
+// Verifiable with the checksum recorded when it was applied. Records without
+// a checksum, such as those written by earlier versions of the migrator, are
+// updated with the current one instead of being reported. Repeatable
+// migrations are skipped, since Run applies them again when they change.
+//
+// How mismatches are reported depends on the configured Validation mode:
+// ValidateStrict returns an error wrapping ErrChecksumMismatch, ValidateWarn
+// logs a warning for each changed migration, and ValidateOff skips the check.
+//
+// Returns:
+//   - error: if the database query fails or a migration changed in strict mode
+func (m *migrator) Validate() error {
+	if m.validation == ValidateOff {
+		return nil
+	}
+
+	te, err := m.trackerExists()
+	if err != nil || !te {
+		return err
+	}
+
+	var records []tracker
+	if err := m.track(m.db).Find(&records).Error; err != nil {
+		return err
+	}
+
+	recorded := make(map[string]tracker, len(records))
+	for _, record := range records {
+		if record.done() {
+			recorded[record.Code] = record
+		}
+	}
+
+	var changed []string
+	for _, migration := range m.schema {
+		verifiable, ok := migration.(Verifiable)
+		if !ok || repeatable(migration) {
+			continue
+		}
+
+		record, ok := recorded[migration.GetCode()]
+		if !ok {
+			continue
+		}
+
+		sum := verifiable.Checksum()
+		if record.Checksum == "" {
+			err := m.track(m.db).Where("code = ?", record.Code).Update("checksum", sum).Error
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if record.Checksum != sum {
+			changed = append(changed, record.Code)
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if m.validation == ValidateWarn {
+		for _, code := range changed {
+			terminal.Warning("Migration " + code + " has changed since it was applied")
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrChecksumMismatch, strings.Join(changed, ", "))
 }
 
 // AddSchema registers one or more migrations to the migrator's schema.
@@ -101,21 +413,69 @@ func (m *migrator) AddSchema(schemas ...Migration) {
 	}
 }
 
-// Run applies all pending migrations in the order they were defined.
-// It first ensures the tracker table exists, then checks each migration
-// by code. If a migration has not been applied, it is executed inside
-// a database transaction. Successfully applied migrations are recorded.
-// After successful execution of all migrations, the schema is cleared.
+// Run applies all pending migrations in the order they were defined, moving
+// migrations after the ones they require (see Dependent). Duplicate codes,
+// missing requirements and cycles are reported before touching the database.
+// It then ensures the tracker table exists, validates the checksums of the
+// applied migrations and checks each migration by code. If a migration has
+// not been applied, it is executed inside a database transaction.
+// Successfully applied migrations are recorded. Applied repeatable migrations
+// whose checksum changed are executed again. The registered migrations are
+// kept, so the same migrator can run them again, roll them back or report
+// their status afterwards.
+//
+// The whole run holds a PostgreSQL advisory lock, so when several instances
+// start at once only one migrates while the others wait, or skip the run
+// when configured with WithLockSkip.
+//
+// Once the lock is acquired, the hooks configured with WithBeforeRun and
+// WithAfterRun are called around the run, and WithBeforeMigration and
+// WithAfterMigration around each executed migration. The observers set with
+// WithObserver receive an Event for the run and for each migration.
 //
 // Returns:
 //   - error: if any migration step fails
 func (m *migrator) Run() error {
-	err := m.migrateTracker()
+	return m.locked(m.run)
+}
+
+// run applies the pending migrations between the run hooks and events, see Run.
+func (m *migrator) run() (err error) {
+	if m.hooks.beforeRun != nil {
+		if err := m.hooks.beforeRun(); err != nil {
+			return err
+		}
+	}
+
+	start := time.Now()
+	m.emit(Event{Kind: EventRunStarted})
+	defer func() {
+		m.emit(Event{Kind: EventRunFinished, Duration: time.Since(start), Err: err})
+		if m.hooks.afterRun != nil {
+			m.hooks.afterRun(err)
+		}
+	}()
+
+	return m.migrate()
+}
+
+// migrate applies the pending migrations, see Run.
+func (m *migrator) migrate() error {
+	migrations, err := m.ordered()
 	if err != nil {
 		return err
 	}
 
-	for _, migration := range m.schema {
+	err = m.migrateTracker()
+	if err != nil {
+		return err
+	}
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
 		exists, err := m.checkMigration(migration.GetCode())
 		if err != nil {
 			return err
@@ -123,15 +483,64 @@ func (m *migrator) Run() error {
 
 		if !exists {
 			terminal.About("Migrate", migration.GetName())
-			err := m.db.Transaction(migration.Execute)
+			err := m.observe(EventMigrationApplied, migration, func(statements *int) error {
+				return m.apply(migration, statements)
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		outdated, err := m.outdated(migration)
+		if err != nil {
+			return err
+		}
+
+		if outdated {
+			terminal.About("Reapply", migration.GetName())
+			err := m.observe(EventMigrationReapplied, migration, func(statements *int) error {
+				return m.reapply(migration.(Repeatable), statements)
+			})
 			if err != nil {
 				return err
 			}
 		}
 	}
 
-	// Clear the schema after successful execution
-	m.schema = nil
+	return nil
+}
+
+// RunSchemas applies the registered migrations to each of the given schemas,
+// one after the other, as if Run were called by a migrator configured with
+// WithSchema for each of them. Every schema keeps its own tracking table, so
+// migrations are tracked per schema.
+//
+// Every schema name is validated before migrating any of them, see
+// WithSchema. It stops at the first schema that fails.
+//
+// Parameters:
+//   - schemas: the PostgreSQL schemas to migrate, e.g. one per tenant
+//
+// Returns:
+//   - error: ErrInvalidSchema for an invalid schema name, or if any
+//     migration step fails, identifying the schema
+func (m *migrator) RunSchemas(schemas ...string) error {
+	for _, schema := range schemas {
+		if err := checkSchema(schema); err != nil {
+			return err
+		}
+	}
+
+	for _, schema := range schemas {
+		tenant := *m
+		tenant.dbSchema = schema
+
+		terminal.About("Schema", schema)
+		if err := tenant.Run(); err != nil {
+			return fmt.Errorf("schema %s: %w", schema, err)
+		}
+	}
 
 	return nil
 }
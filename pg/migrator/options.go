@@ -0,0 +1,124 @@
+package migrator
+
+import "time"
+
+// Option configures optional behavior of the migrator.
+type Option func(*migrator)
+
+// Validation defines how the migrator reacts when an applied migration
+// no longer matches the checksum recorded in the tracking table.
+type Validation int
+
+const (
+	// ValidateStrict makes Run fail before applying any migration.
+	ValidateStrict Validation = iota
+	// ValidateWarn logs a warning for each changed migration and continues.
+	ValidateWarn
+	// ValidateOff skips checksum verification.
+	ValidateOff
+)
+
+// WithValidation sets how checksum mismatches of applied migrations are handled.
+// The default is ValidateStrict.
+func WithValidation(validation Validation) Option {
+	return func(m *migrator) {
+		m.validation = validation
+	}
+}
+
+// WithLockKey sets the PostgreSQL advisory lock key shared by every instance
+// that migrates the same database. The default is DefaultLockKey.
+func WithLockKey(key int64) Option {
+	return func(m *migrator) {
+		m.lock.key = key
+	}
+}
+
+// WithLockTimeout limits how long the migrator waits for the advisory lock
+// before failing with ErrLockTimeout. A zero timeout waits forever.
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(m *migrator) {
+		m.lock.timeout = timeout
+	}
+}
+
+// WithLockSkip makes the migrator skip the run, instead of waiting, when
+// another instance holds the advisory lock.
+func WithLockSkip() Option {
+	return func(m *migrator) {
+		m.lock.skip = true
+	}
+}
+
+// WithoutLock disables the advisory lock, for databases migrated by a single instance.
+func WithoutLock() Option {
+	return func(m *migrator) {
+		m.lock.disabled = true
+	}
+}
+
+// WithSchema sets the PostgreSQL schema to migrate. The schema is created if
+// it does not exist, holds its own tracking table and is placed first in the
+// search_path while migrating, so unqualified names resolve to it.
+//
+// The name may contain letters of any case, digits, underscores, dollar signs
+// and hyphens, and is kept as written; any other name makes the migrator fail
+// with ErrInvalidSchema before running any statement.
+func WithSchema(schema string) Option {
+	return func(m *migrator) {
+		m.dbSchema = schema
+	}
+}
+
+// WithTrackerTable sets the name of the tracking table. The default is "migrations".
+func WithTrackerTable(table string) Option {
+	return func(m *migrator) {
+		m.table = table
+	}
+}
+
+// WithSearchPath sets the schemas used as search_path while migrating.
+// When a schema is also set with WithSchema, it is placed before them.
+func WithSearchPath(schemas ...string) Option {
+	return func(m *migrator) {
+		m.searchPath = schemas
+	}
+}
+
+// WithBeforeRun sets a function called when a run starts, once the advisory
+// lock is acquired. Returning an error aborts the run.
+func WithBeforeRun(fn func() error) Option {
+	return func(m *migrator) {
+		m.hooks.beforeRun = fn
+	}
+}
+
+// WithAfterRun sets a function called when a run finishes, with its error.
+func WithAfterRun(fn func(err error)) Option {
+	return func(m *migrator) {
+		m.hooks.afterRun = fn
+	}
+}
+
+// WithBeforeMigration sets a hook called before each migration executes.
+func WithBeforeMigration(hook MigrationHook) Option {
+	return func(m *migrator) {
+		m.hooks.beforeMigration = hook
+	}
+}
+
+// WithAfterMigration sets a hook called after each migration executes,
+// before it is recorded in the tracking table.
+func WithAfterMigration(hook MigrationHook) Option {
+	return func(m *migrator) {
+		m.hooks.afterMigration = hook
+	}
+}
+
+// WithObserver adds an observer that receives the events of runs, migrations
+// and rollbacks. It can be used several times to add more observers.
+func WithObserver(observer Observer) Option {
+	return func(m *migrator) {
+		m.observers = append(m.observers, observer)
+	}
+}
@@ -0,0 +1,86 @@
+package migrator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOptions tests that options configure the migrator.
+func TestOptions(t *testing.T) {
+	m := New(nil)
+	assert.Equal(t, ValidateStrict, m.validation)
+	assert.Equal(t, lock{key: DefaultLockKey}, m.lock)
+
+	m = New(nil,
+		WithValidation(ValidateWarn),
+		WithLockKey(42),
+		WithLockTimeout(time.Minute),
+		WithLockSkip(),
+	)
+	assert.Equal(t, ValidateWarn, m.validation)
+	assert.Equal(t, lock{key: 42, timeout: time.Minute, skip: true}, m.lock)
+}
+
+// TestLockedWithoutLock tests that a migrator without lock runs the operation directly.
+func TestLockedWithoutLock(t *testing.T) {
+	expected := errors.New("failed")
+	m := New(nil, WithoutLock())
+
+	calls := 0
+	err := m.locked(func() error {
+		calls++
+		return expected
+	})
+
+	assert.Equal(t, 1, calls)
+	assert.ErrorIs(t, err, expected)
+}
+
+// TestSchemaOptions tests the tracking table and search_path derived from the schema options.
+func TestSchemaOptions(t *testing.T) {
+	tests := []struct {
+		name  string   // name of the test case
+		opts  []Option // the options to apply
+		table string   // the expected tracking table
+		path  []string // the expected search_path
+	}{
+		{
+			name:  "Default settings",
+			table: "migrations",
+		},
+		{
+			name:  "Custom tracking table",
+			opts:  []Option{WithTrackerTable("schema_versions")},
+			table: "schema_versions",
+		},
+		{
+			name:  "Target schema",
+			opts:  []Option{WithSchema("tenant")},
+			table: "tenant.migrations",
+			path:  []string{"tenant", "public"},
+		},
+		{
+			name:  "Target schema with search path",
+			opts:  []Option{WithSchema("tenant"), WithSearchPath("tenant", "shared", "public")},
+			table: "tenant.migrations",
+			path:  []string{"tenant", "shared", "public"},
+		},
+		{
+			name:  "Search path only",
+			opts:  []Option{WithSearchPath("shared", "public")},
+			table: "migrations",
+			path:  []string{"shared", "public"},
+		},
+	}
+
+	for _, item := range tests {
+		t.Run(item.name, func(t *testing.T) {
+			m := New(nil, item.opts...)
+			assert.Equal(t, item.table, m.trackerTable())
+			assert.Equal(t, item.path, m.path())
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package migrator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dependent is implemented by migrations that must be applied after other
+// migrations, identified by their codes. It allows modules that register
+// their migrations from separate packages to declare the order they need.
+type Dependent interface {
+	Migration
+	GetRequires() []string
+}
+
+// sortMigrations orders the migrations so that every migration comes after
+// the migrations it requires. Migrations without a relative order keep the
+// order in which they were registered.
+//
+// Parameters:
+//   - migrations: the registered migrations, in registration order
+//
+// Returns:
+//   - []Migration: the migrations in the order they must be applied
+//   - error: ErrDuplicateMigration if two migrations share a code,
+//     ErrMissingDependency if a required code is not registered,
+//     or ErrDependencyCycle if the requirements form a cycle
+func sortMigrations(migrations []Migration) ([]Migration, error) {
+	registered := make(map[string]bool, len(migrations))
+	for _, migration := range migrations {
+		if registered[migration.GetCode()] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateMigration, migration.GetCode())
+		}
+		registered[migration.GetCode()] = true
+	}
+
+	requires := make([][]string, len(migrations))
+	for index, migration := range migrations {
+		dependent, ok := migration.(Dependent)
+		if !ok {
+			continue
+		}
+
+		for _, code := range dependent.GetRequires() {
+			if !registered[code] {
+				return nil, fmt.Errorf("%w: %s requires %s", ErrMissingDependency, migration.GetCode(), code)
+			}
+		}
+		requires[index] = dependent.GetRequires()
+	}
+
+	sorted := make([]Migration, 0, len(migrations))
+	placed := make(map[string]bool, len(migrations))
+	done := make([]bool, len(migrations))
+
+	for len(sorted) < len(migrations) {
+		progress := false
+
+		for index, migration := range migrations {
+			if done[index] || !satisfied(requires[index], placed) {
+				continue
+			}
+
+			sorted = append(sorted, migration)
+			placed[migration.GetCode()] = true
+			done[index] = true
+			progress = true
+			break
+		}
+
+		if !progress {
+			var cycle []string
+			for index, migration := range migrations {
+				if !done[index] {
+					cycle = append(cycle, migration.GetCode())
+				}
+			}
+			return nil, fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(cycle, ", "))
+		}
+	}
+
+	return sorted, nil
+}
+
+// satisfied reports whether every required code has already been placed.
+func satisfied(requires []string, placed map[string]bool) bool {
+	for _, code := range requires {
+		if !placed[code] {
+			return false
+		}
+	}
+	return true
+}
+
+// ordered returns the registered migrations in the order they must be applied.
+func (m *migrator) ordered() ([]Migration, error) {
+	return sortMigrations(m.schema)
+}
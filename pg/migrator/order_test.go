@@ -0,0 +1,80 @@
+package migrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSortMigrations tests ordering migrations by their requirements.
+func TestSortMigrations(t *testing.T) {
+	tests := []struct {
+		name       string      // name of the test case
+		migrations []Migration // the migrations in registration order
+		expected   []string    // the expected order of codes
+	}{
+		{
+			name: "Registration order without requirements",
+			migrations: []Migration{
+				&SchemaMigration{Code: "users"},
+				&SchemaMigration{Code: "roles"},
+			},
+			expected: []string{"users", "roles"},
+		},
+		{
+			name: "Requirement registered later",
+			migrations: []Migration{
+				&SchemaMigration{Code: "profiles", Requires: []string{"users"}},
+				&SchemaMigration{Code: "roles"},
+				&SchemaMigration{Code: "users"},
+			},
+			expected: []string{"roles", "users", "profiles"},
+		},
+		{
+			name: "Mixed migration types",
+			migrations: []Migration{
+				&DataMigration{Code: "seed", Requires: []string{"0002", "0001"}},
+				&SQLMigration{Code: "0002", Requires: []string{"0001"}},
+				&SQLMigration{Code: "0001"},
+			},
+			expected: []string{"0001", "0002", "seed"},
+		},
+	}
+
+	for _, item := range tests {
+		t.Run(item.name, func(t *testing.T) {
+			sorted, err := sortMigrations(item.migrations)
+			require.NoError(t, err)
+
+			codes := []string{}
+			for _, migration := range sorted {
+				codes = append(codes, migration.GetCode())
+			}
+			assert.Equal(t, item.expected, codes)
+		})
+	}
+}
+
+// TestSortMigrationsErrors tests the detection of missing requirements and cycles.
+func TestSortMigrationsErrors(t *testing.T) {
+	_, err := sortMigrations([]Migration{
+		&SchemaMigration{Code: "profiles", Requires: []string{"users"}},
+	})
+	assert.ErrorIs(t, err, ErrMissingDependency)
+
+	_, err = sortMigrations([]Migration{
+		&SchemaMigration{Code: "users"},
+		&SchemaMigration{Code: "profiles", Requires: []string{"accounts"}},
+		&SchemaMigration{Code: "accounts", Requires: []string{"profiles"}},
+	})
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+	assert.Contains(t, err.Error(), "profiles, accounts")
+
+	_, err = sortMigrations([]Migration{
+		&SchemaMigration{Code: "users"},
+		&DataMigration{Code: "users"},
+	})
+	assert.ErrorIs(t, err, ErrDuplicateMigration)
+	assert.Contains(t, err.Error(), "users")
+}
@@ -0,0 +1,143 @@
+package migrator
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pinzlab/goutil/terminal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// PlannedMigration describes a pending migration and the SQL statements
+// it would execute if the migrator were run.
+type PlannedMigration struct {
+	Code       string   // Unique code identifier of the pending migration
+	Name       string   // Human-readable name of the pending migration
+	Statements []string // SQL statements the migration would execute, in order
+}
+
+// Plan is the ordered list of pending migrations returned by the migrator's Plan method.
+type Plan []PlannedMigration
+
+// recorder is a GORM logger that collects the statements traced while a
+// migration runs in dry-run mode. Queries are skipped, because GORM still
+// executes them to inspect the database during a dry run.
+type recorder struct {
+	logger.Interface
+	statements []string
+}
+
+// newRecorder creates a recorder that discards every regular log message.
+func newRecorder() *recorder {
+	return &recorder{Interface: logger.Discard}
+}
+
+// LogMode keeps the recorder as the active logger regardless of the level.
+func (r *recorder) LogMode(logger.LogLevel) logger.Interface {
+	return r
+}
+
+// Trace records the statement unless it is a read-only query.
+func (r *recorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	statement := strings.TrimSpace(sql)
+	if statement == "" || isQuery(statement) {
+		return
+	}
+	r.statements = append(r.statements, statement)
+}
+
+// isQuery reports whether the statement only reads from the database.
+func isQuery(statement string) bool {
+	keyword := strings.ToUpper(strings.SplitN(statement, " ", 2)[0])
+	return keyword == "SELECT" || keyword == "SHOW" || keyword == "WITH"
+}
+
+// Plan returns the pending migrations, and the repeatable migrations that
+// changed since they were applied, in the order they would be applied,
+// together with the SQL each one would execute. Nothing is written to the
+// database: every migration is executed on a GORM dry-run session, which
+// only reads the catalog (e.g. to let AutoMigrate decide between creating
+// and altering a table) and records the statements instead of running them.
+//
+// Statements are computed against the current state of the database, so the
+// DDL of a migration that depends on an earlier pending one may differ from
+// the DDL executed by Run.
+//
+// Returns:
+//   - Plan: the pending migrations and their statements
+//   - error: if the tracking table cannot be queried or a migration fails to build
+func (m *migrator) Plan() (plan Plan, err error) {
+	err = m.connected(func() error {
+		plan, err = m.plan()
+		return err
+	})
+	return plan, err
+}
+
+// plan builds the plan of pending migrations, see Plan.
+func (m *migrator) plan() (Plan, error) {
+	te, err := m.trackerExists()
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := m.ordered()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := Plan{}
+	for _, migration := range migrations {
+		if te {
+			exists, err := m.checkMigration(migration.GetCode())
+			if err != nil {
+				return nil, err
+			}
+			outdated, err := m.outdated(migration)
+			if err != nil {
+				return nil, err
+			}
+			if exists && !outdated {
+				continue
+			}
+		}
+
+		rec := newRecorder()
+		dry := m.db.Session(&gorm.Session{DryRun: true, SkipDefaultTransaction: true, Logger: rec})
+		if err := migration.Execute(dry); err != nil {
+			return nil, err
+		}
+		if detached(migration) {
+			if err := migration.(Detached).ExecuteDetached(dry); err != nil {
+				return nil, err
+			}
+		}
+
+		plan = append(plan, PlannedMigration{
+			Code:       migration.GetCode(),
+			Name:       migration.GetName(),
+			Statements: rec.statements,
+		})
+	}
+
+	return plan, nil
+}
+
+// Print writes the plan to the log through the terminal package, listing
+// each pending migration followed by its statements.
+func (p Plan) Print() {
+	if len(p) == 0 {
+		terminal.Info("No pending migrations")
+		return
+	}
+
+	for _, migration := range p {
+		terminal.About("Pending", migration.Code+" "+migration.Name)
+		for _, statement := range migration.Statements {
+			terminal.Script(statement)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package migrator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRun opens a PostgreSQL dry-run session that never connects to a server
+// and records its statements in the given recorder.
+func dryRun(t *testing.T, rec *recorder) *gorm.DB {
+	db, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return db.Session(&gorm.Session{DryRun: true, SkipDefaultTransaction: true, Logger: rec})
+}
+
+// TestRecorderStatements tests that a dry-run execution records the statements of a migration.
+func TestRecorderStatements(t *testing.T) {
+	dep := "CREATE EXTENSION IF NOT EXISTS unaccent"
+	enum := &Enum{Name: "status", Values: []string{"active", "inactive"}}
+	migration := &SchemaMigration{
+		Code:         "first",
+		Name:         "First migration",
+		Dependencies: []string{dep},
+		Enums:        []*Enum{enum},
+	}
+
+	rec := newRecorder()
+	require.NoError(t, migration.Execute(dryRun(t, rec)))
+
+	require.GreaterOrEqual(t, len(rec.statements), 2)
+	assert.Equal(t, dep, rec.statements[0])
+	assert.Equal(t, strings.TrimSpace(enum.GetScript()), rec.statements[1])
+}
+
+// TestIsQuery tests the detection of read-only statements skipped by the recorder.
+func TestIsQuery(t *testing.T) {
+	tests := []struct {
+		statement string // the statement to check
+		expected  bool   // whether the statement is read-only
+	}{
+		{statement: "SELECT count(*) FROM information_schema.tables", expected: true},
+		{statement: "select CURRENT_SCHEMA()", expected: true},
+		{statement: "WITH t AS (SELECT 1) SELECT * FROM t", expected: true},
+		{statement: "CREATE TABLE users (id bigserial)", expected: false},
+		{statement: "DO $$ BEGIN END $$;", expected: false},
+	}
+
+	for _, item := range tests {
+		t.Run(item.statement, func(t *testing.T) {
+			assert.Equal(t, item.expected, isQuery(item.statement))
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package migrator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// plain matches the identifiers that PostgreSQL keeps as written without quotes.
+var plain = regexp.MustCompile(`^[a-z_][a-z0-9_$]*$`)
+
+// schemaName matches the schema names accepted by WithSchema and RunSchemas.
+var schemaName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$-]*$`)
+
+// qualify prefixes name with the given schema, unless no schema is given
+// or the name is already qualified. The schema is quoted when needed, see ident.
+func qualify(schema, name string) string {
+	if schema == "" || strings.Contains(name, ".") {
+		return name
+	}
+	return ident(schema) + "." + name
+}
+
+// namespace returns the SQL expression for the OID of the given schema,
+// or of the current schema (the first one in search_path) when empty.
+func namespace(schema string) string {
+	if schema == "" {
+		return "current_schema()::regnamespace"
+	}
+	return quote(ident(schema)) + "::regnamespace"
+}
+
+// ident returns name as a PostgreSQL identifier, quoted only when it has
+// uppercase letters or other characters, so it is kept as written.
+func ident(name string) string {
+	if plain.MatchString(name) {
+		return name
+	}
+	return quoteIdent(name)
+}
+
+// quoteIdent returns name as a quoted PostgreSQL identifier, doubling double quotes.
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// checkSchema fails with ErrInvalidSchema when name is not a valid schema name:
+// letters, digits, underscores, dollar signs and hyphens, up to 63 characters,
+// not starting with a digit.
+func checkSchema(name string) error {
+	if len(name) > 63 || !schemaName.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidSchema, name)
+	}
+	return nil
+}
+
+// identifier builds an object name from the given parts, such as a table and its
+// columns, replacing the characters of expressions that are not valid in unquoted
+// identifiers, e.g. lower(email) becomes lower_email.
+func identifier(parts ...string) string {
+	var name strings.Builder
+	for _, part := range parts {
+		for _, r := range strings.ToLower(part) {
+			valid := r == '_' || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+			if !valid {
+				r = '_'
+			}
+			if r == '_' && (name.Len() == 0 || strings.HasSuffix(name.String(), "_")) {
+				continue
+			}
+			name.WriteRune(r)
+		}
+		if name.Len() > 0 && !strings.HasSuffix(name.String(), "_") {
+			name.WriteRune('_')
+		}
+	}
+	return strings.TrimSuffix(name.String(), "_")
+}
@@ -0,0 +1,61 @@
+package migrator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestQualify tests qualifying names with a schema.
+func TestQualify(t *testing.T) {
+	assert.Equal(t, "users", qualify("", "users"))
+	assert.Equal(t, "tenant.users", qualify("tenant", "users"))
+	assert.Equal(t, "public.users", qualify("tenant", "public.users"))
+	assert.Equal(t, `"Tenant-A".users`, qualify("Tenant-A", "users"))
+
+	assert.Equal(t, "current_schema()::regnamespace", namespace(""))
+	assert.Equal(t, "'tenant'::regnamespace", namespace("tenant"))
+	assert.Equal(t, `'"Tenant-A"'::regnamespace`, namespace("Tenant-A"))
+	assert.Equal(t, `'"a""b"'::regnamespace`, namespace(`a"b`))
+}
+
+// TestCheckSchema tests the validation of schema names.
+func TestCheckSchema(t *testing.T) {
+	tests := []struct {
+		name  string // Schema name
+		valid bool   // Whether the name is accepted
+	}{
+		{name: "tenant", valid: true},
+		{name: "Tenant-A", valid: true},
+		{name: "_tenant$1", valid: true},
+		{name: "1tenant"},
+		{name: ""},
+		{name: "tenant; DROP TABLE users"},
+		{name: `tenant"`},
+		{name: strings.Repeat("a", 64)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkSchema(test.name)
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidSchema)
+			}
+		})
+	}
+
+	m := New(nil, WithSchema("tenant; DROP TABLE users"))
+	assert.ErrorIs(t, m.connected(func() error { return nil }), ErrInvalidSchema)
+	assert.ErrorIs(t, New(nil).RunSchemas("ok", "not ok"), ErrInvalidSchema)
+}
+
+// TestIdentifier tests building object names from tables, columns and expressions.
+func TestIdentifier(t *testing.T) {
+	assert.Equal(t, "idx_users_email", identifier("idx", "users", "email"))
+	assert.Equal(t, "idx_users_lower_email", identifier("idx", "users", "lower(email)"))
+	assert.Equal(t, "uni_orders_user_id_date_trunc_day_created_at", identifier("uni", "orders", "user_id", "date_trunc('day', created_at)"))
+	assert.Equal(t, "chk_tenant_orders", identifier("chk", "tenant.orders"))
+}
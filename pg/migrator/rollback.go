@@ -0,0 +1,153 @@
+package migrator
+
+import (
+	"fmt"
+
+	"github.com/pinzlab/goutil/terminal"
+	"gorm.io/gorm"
+)
+
+// applied returns the given migrations that are already recorded in the
+// tracking table, preserving their order.
+//
+// Parameters:
+//   - migrations: the registered migrations, in the order Run applies them
+//
+// Returns:
+//   - []Migration: the applied migrations in the same order
+//   - error: if the database query fails
+func (m *migrator) applied(migrations []Migration) ([]Migration, error) {
+	te, err := m.trackerExists()
+	if err != nil || !te {
+		return nil, err
+	}
+
+	var records []tracker
+	if err := m.track(m.db).Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	recorded := make(map[string]bool, len(records))
+	for _, record := range records {
+		recorded[record.Code] = record.done()
+	}
+
+	var result []Migration
+	for _, migration := range migrations {
+		if recorded[migration.GetCode()] {
+			result = append(result, migration)
+		}
+	}
+	return result, nil
+}
+
+// Rollback reverts the last n applied migrations, in the reverse of the order
+// Run applies them.
+// Each migration is reverted inside its own database transaction, and its record
+// is removed from the tracking table once its down steps succeed.
+//
+// Before touching the database, every selected migration is checked to be
+// reversible, so a rollback never stops halfway because of missing down steps.
+// Like Run, the rollback holds the migrator's advisory lock.
+//
+// Parameters:
+//   - n: the number of applied migrations to revert
+//
+// Returns:
+//   - error: if a migration is irreversible or any rollback step fails
+func (m *migrator) Rollback(n int) error {
+	migrations, err := m.ordered()
+	if err != nil {
+		return err
+	}
+
+	return m.locked(func() error {
+		applied, err := m.applied(migrations)
+		if err != nil {
+			return err
+		}
+
+		if n <= 0 {
+			return nil
+		}
+		if n > len(applied) {
+			n = len(applied)
+		}
+
+		return m.rollback(applied[len(applied)-n:])
+	})
+}
+
+// RollbackTo reverts every applied migration that Run applies after the
+// migration identified by code, in reverse order. The target migration
+// itself remains applied.
+//
+// Parameters:
+//   - code: the unique code of the migration to roll back to
+//
+// Returns:
+//   - error: if the code is not registered, a migration is irreversible or any rollback step fails
+func (m *migrator) RollbackTo(code string) error {
+	migrations, err := m.ordered()
+	if err != nil {
+		return err
+	}
+
+	target := find(migrations, code)
+	if target < 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownMigration, code)
+	}
+
+	after := make(map[string]bool)
+	for _, migration := range migrations[target+1:] {
+		after[migration.GetCode()] = true
+	}
+
+	return m.locked(func() error {
+		applied, err := m.applied(migrations)
+		if err != nil {
+			return err
+		}
+
+		var pending []Migration
+		for _, migration := range applied {
+			if after[migration.GetCode()] {
+				pending = append(pending, migration)
+			}
+		}
+
+		return m.rollback(pending)
+	})
+}
+
+// rollback reverts the given migrations from last to first, deleting their
+// records from the tracking table.
+func (m *migrator) rollback(migrations []Migration) error {
+	reversibles := make([]Reversible, 0, len(migrations))
+	for _, migration := range migrations {
+		reversible, ok := migration.(Reversible)
+		if !ok || !reversible.CanRollback() {
+			return fmt.Errorf("%w: %s", ErrIrreversible, migration.GetCode())
+		}
+		reversibles = append(reversibles, reversible)
+	}
+
+	for i := len(reversibles) - 1; i >= 0; i-- {
+		reversible := reversibles[i]
+
+		terminal.About("Rollback", reversible.GetName())
+		err := m.observe(EventMigrationRolledBack, reversible, func(statements *int) error {
+			return m.db.Transaction(func(tx *gorm.DB) error {
+				if err := reversible.Rollback(counted(tx, statements)); err != nil {
+					return err
+				}
+				return m.track(tx).Where("code = ?", reversible.GetCode()).Delete(&tracker{}).Error
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,9 @@
 package migrator
 
 import (
+	"strings"
+
+	"github.com/pinzlab/goutil/terminal"
 	"gorm.io/gorm"
 )
 
@@ -12,17 +15,23 @@ import (
 // must be executed first, followed by other components like enums, entities,
 // unique indexes, foreign keys, stored procedures, and data insertions.
 //
-// Once all operations are successful, the SchemaMigration is recorded in a tracking table.
+// Once all operations are successful, the migrator records the SchemaMigration in a tracking table.
 type SchemaMigration struct {
-	Code         string        // Unique code identifier for the SchemaMigration (max 20 chars)
-	Name         string        // Human-readable name for the SchemaMigration (max 100 chars)
-	Description  string        // Optional detailed description of the SchemaMigration (max 255 chars)
-	Dependencies []string      // Raw SQL dependencies to execute before other steps
-	Enums        []*Enum       // ENUM types to be created conditionally
-	Entities     []interface{} // GORM models to be auto-migrated
-	Uniques      []*Unique     // Unique constraints to be added via raw SQL
-	ForeignKeys  []*Foreign    // Foreign key constraints to be added via raw SQL
-	Procedures   []string      // Stored procedures or functions in SQL
+	Code          string        // Unique code identifier for the SchemaMigration (max 20 chars)
+	Name          string        // Human-readable name for the SchemaMigration (max 100 chars)
+	Description   string        // Optional detailed description of the SchemaMigration (max 255 chars)
+	Dependencies  []string      // Raw SQL dependencies to execute before other steps
+	Requires      []string      // Codes of the migrations that must be applied before this one
+	Enums         []*Enum       // ENUM types to be created conditionally
+	Entities      []interface{} // GORM models to be auto-migrated
+	Uniques       []*Unique     // Unique constraints to be added via raw SQL
+	Indexes       []*Index      // Indexes to be created, concurrent ones after the transaction
+	Checks        []*Check      // CHECK constraints to be added via raw SQL
+	ForeignKeys   []*Foreign    // Foreign key constraints to be added via raw SQL
+	Procedures    []string      // Stored procedures or functions in SQL
+	Outside       []string      // Raw SQL statements run last, outside of any transaction, e.g. VACUUM
+	NoTransaction bool          // Whether to run all steps without a transaction, each statement committing on its own
+	Down          []string      // Raw SQL statements that revert the SchemaMigration, run in order on rollback
 }
 
 // GetCode returns the unique identifier for the migration
@@ -35,6 +44,16 @@ func (m *SchemaMigration) GetName() string {
 	return m.Name
 }
 
+// GetDescription returns the detailed description for the migration
+func (m *SchemaMigration) GetDescription() string {
+	return m.Description
+}
+
+// GetRequires returns the codes of the migrations that must be applied first
+func (m *SchemaMigration) GetRequires() []string {
+	return m.Requires
+}
+
 // Execute performs the schema migration within a transaction
 func (m *SchemaMigration) Execute(tx *gorm.DB) error {
 	// Execute dependencies
@@ -44,11 +63,25 @@ func (m *SchemaMigration) Execute(tx *gorm.DB) error {
 		}
 	}
 
-	// Create ENUM types
+	// Create ENUM types and evolve the existing ones
 	for _, enum := range m.Enums {
 		if err := tx.Exec(enum.GetScript()).Error; err != nil {
 			return err
 		}
+		if err := tx.Exec(enum.GetUpdateScript()).Error; err != nil {
+			return err
+		}
+
+		if tx.DryRun {
+			continue
+		}
+		diff, err := enum.Diff(tx)
+		if err != nil {
+			return err
+		}
+		if len(diff.Removed) > 0 {
+			terminal.Warning("Enum " + enum.Name + " has values not declared in code: " + strings.Join(diff.Removed, ", "))
+		}
 	}
 
 	// Auto-migrate entities
@@ -65,6 +98,23 @@ func (m *SchemaMigration) Execute(tx *gorm.DB) error {
 		}
 	}
 
+	// Create indexes, except the concurrent ones
+	for _, index := range m.Indexes {
+		if index.Concurrently {
+			continue
+		}
+		if err := tx.Exec(index.GetScript()).Error; err != nil {
+			return err
+		}
+	}
+
+	// Add check constraints
+	for _, check := range m.Checks {
+		if err := tx.Exec(check.GetScript()).Error; err != nil {
+			return err
+		}
+	}
+
 	// Add foreign key constraints
 	for _, fk := range m.ForeignKeys {
 		if err := tx.Exec(fk.GetScript()).Error; err != nil {
@@ -79,10 +129,86 @@ func (m *SchemaMigration) Execute(tx *gorm.DB) error {
 		}
 	}
 
-	// Save the migration record to the tracking table.
-	if err := tx.Create(&tracker{Code: m.Code, Name: m.Name, Description: m.Description}).Error; err != nil {
-		return err
+	return nil
+}
+
+// InTransaction reports whether the schema migration runs inside a transaction
+func (m *SchemaMigration) InTransaction() bool {
+	return !m.NoTransaction
+}
+
+// HasDetached reports whether the schema migration creates indexes concurrently
+// or runs statements outside of a transaction
+func (m *SchemaMigration) HasDetached() bool {
+	if len(m.Outside) > 0 {
+		return true
 	}
+	for _, index := range m.Indexes {
+		if index.Concurrently {
+			return true
+		}
+	}
+	return false
+}
 
+// ExecuteDetached creates the concurrent indexes outside of a transaction,
+// dropping first the invalid ones left behind by a failed build, and then
+// runs the outside statements
+func (m *SchemaMigration) ExecuteDetached(db *gorm.DB) error {
+	for _, index := range m.Indexes {
+		if !index.Concurrently {
+			continue
+		}
+		if err := index.repair(db); err != nil {
+			return err
+		}
+		if err := db.Exec(index.GetScript()).Error; err != nil {
+			return err
+		}
+	}
+	for _, statement := range m.Outside {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// CanRollback reports whether the schema migration defines down steps
+func (m *SchemaMigration) CanRollback() bool {
+	return len(m.Down) > 0
+}
+
+// Rollback reverts the schema migration within a transaction by executing its down steps
+func (m *SchemaMigration) Rollback(tx *gorm.DB) error {
+	return rollback(tx, m.Code, m.Down)
+}
+
+// Checksum returns a hash of what the schema migration declares: dependencies,
+// enums, uniques, indexes, check constraints, foreign keys, procedures and outside
+// statements. The declarations are hashed rather than the SQL generated from them,
+// so the checksum does not change when the library renders them differently.
+// Entities are not included, since AutoMigrate is expected to evolve them
+// across migrations.
+func (m *SchemaMigration) Checksum() string {
+	var scripts []string
+	scripts = append(scripts, m.Dependencies...)
+	for _, enum := range m.Enums {
+		scripts = append(scripts, declared(enum))
+	}
+	for _, unique := range m.Uniques {
+		scripts = append(scripts, declared(unique))
+	}
+	for _, index := range m.Indexes {
+		scripts = append(scripts, declared(index))
+	}
+	for _, check := range m.Checks {
+		scripts = append(scripts, declared(check))
+	}
+	for _, fk := range m.ForeignKeys {
+		scripts = append(scripts, declared(fk))
+	}
+	scripts = append(scripts, m.Procedures...)
+	scripts = append(scripts, m.Outside...)
+	return checksum(scripts...)
+}
@@ -0,0 +1,426 @@
+package migrator
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultBatch is the number of rows inserted per statement batch when a Seed
+// does not set Batch.
+const defaultBatch = 500
+
+// Format identifies the encoding of a seed file.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"  // Comma separated values with a header row
+	FormatJSON Format = "json" // An array of objects
+	FormatYAML Format = "yaml" // A sequence of mappings
+)
+
+// ColumnType defines how the values of a seed file column are converted
+// before being inserted.
+type ColumnType int
+
+const (
+	TypeAuto   ColumnType = iota // Keep the decoded value: strings for CSV, numbers, booleans, lists and objects for JSON and YAML
+	TypeString                   // Text, empty CSV cells remain empty strings instead of null
+	TypeInt                      // 64-bit integer
+	TypeFloat                    // 64-bit floating point number
+	TypeBool                     // Boolean, as accepted by strconv.ParseBool
+	TypeTime                     // Timestamp in RFC 3339 or date in "2006-01-02" format
+	TypeJSON                     // JSON document, inserted as is
+)
+
+// Seed represents a table whose rows are read from a CSV, JSON or YAML file,
+// skipping rows that already exist based on specified columns, like Entity.
+//
+// CSV files must start with a header row naming the columns, and empty cells
+// are inserted as null. JSON files hold an array of objects and YAML files a
+// sequence of mappings, whose keys name the columns; missing keys are null.
+type Seed struct {
+	Table         string                // Table name
+	Check         []string              // Columns to check for existing rows
+	Columns       []string              // Columns to insert, all the columns of the file when empty
+	Types         map[string]ColumnType // Conversion applied to the values of each column, TypeAuto when missing
+	Conflict      Conflict              // What to do with rows that already exist, ConflictSkip by default
+	ConflictWhere string                // Predicate of the partial unique index used by ConflictUpdate
+	FS            fs.FS                 // File system containing the file, such as an embed.FS; the OS file system when nil
+	Path          string                // Path of the file within FS
+	Format        Format                // Encoding of the file, inferred from the Path extension when empty
+	Batch         int                   // Rows inserted per batch, 500 when zero
+}
+
+// Each reads the seed file and calls fn with entities holding up to Batch
+// rows each, so large files are inserted in batches. CSV and JSON files are
+// streamed; YAML files are decoded at once.
+//
+// When Columns is empty, the columns of JSON and YAML files are the keys
+// found in any of their objects, so the file is read twice: once to collect
+// them and once to insert the rows.
+//
+// Parameters:
+//   - fn: called for each batch of rows, in file order
+//
+// Returns:
+//   - error: if the file cannot be read, a value cannot be converted or fn fails
+func (s *Seed) Each(fn func(*Entity) error) error {
+	read, err := s.reader()
+	if err != nil {
+		return err
+	}
+
+	columns := s.Columns
+	if len(columns) == 0 && s.format() != FormatCSV {
+		if columns, err = s.keys(read); err != nil {
+			return err
+		}
+	}
+
+	file, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	batch := s.Batch
+	if batch <= 0 {
+		batch = defaultBatch
+	}
+
+	var entity *Entity
+	flush := func() error {
+		if entity == nil || len(entity.Values) == 0 {
+			return nil
+		}
+		err := fn(entity)
+		entity = nil
+		return err
+	}
+
+	row := 0
+	add := func(header []string, values map[string]any) error {
+		row++
+		if entity == nil {
+			entity = s.entity(header)
+			if len(columns) > 0 {
+				entity.Columns = columns
+			}
+		}
+
+		result := make([]any, len(entity.Columns))
+		for index, column := range entity.Columns {
+			value, err := convertSeed(values[column], s.Types[column])
+			if err != nil {
+				return fmt.Errorf("%s row %d column %s: %w", s.Path, row, column, err)
+			}
+			result[index] = value
+		}
+
+		entity.Values = append(entity.Values, result)
+		if len(entity.Values) >= batch {
+			return flush()
+		}
+		return nil
+	}
+
+	if err := read(file, add); err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+// checksum returns a hash of the seed settings and the content of its file.
+func (s *Seed) checksum() string {
+	content, err := s.read()
+	if err != nil {
+		content = []byte(err.Error())
+	}
+
+	var types []string
+	for column, kind := range s.Types {
+		types = append(types, fmt.Sprintf("%s:%d", column, kind))
+	}
+	sort.Strings(types)
+
+	return checksum(
+		s.Table,
+		strings.Join(s.Check, ","),
+		strings.Join(s.Columns, ","),
+		strings.Join(types, ","),
+		strconv.Itoa(int(s.Conflict)),
+		s.ConflictWhere,
+		string(content),
+	)
+}
+
+// entity creates an empty entity for a batch of rows with the given columns.
+func (s *Seed) entity(columns []string) *Entity {
+	return &Entity{
+		Table:         s.Table,
+		Check:         s.Check,
+		Columns:       columns,
+		Conflict:      s.Conflict,
+		ConflictWhere: s.ConflictWhere,
+	}
+}
+
+// reader returns the function reading the rows of the seed file format.
+func (s *Seed) reader() (func(io.Reader, func([]string, map[string]any) error) error, error) {
+	switch s.format() {
+	case FormatCSV:
+		return readCSV, nil
+	case FormatJSON:
+		return readJSON, nil
+	case FormatYAML:
+		return readYAML, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported seed format of %s", ErrInvalidSource, s.Path)
+}
+
+// keys reads the whole seed file and returns, in alphabetical order, the
+// keys found in any of its rows.
+func (s *Seed) keys(read func(io.Reader, func([]string, map[string]any) error) error) ([]string, error) {
+	file, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	found := map[string]any{}
+	err = read(file, func(header []string, _ map[string]any) error {
+		for _, key := range header {
+			found[key] = nil
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys(found), nil
+}
+
+// format returns the configured format or infers it from the file extension.
+func (s *Seed) format() Format {
+	if s.Format != "" {
+		return s.Format
+	}
+
+	switch strings.ToLower(path.Ext(s.Path)) {
+	case ".csv":
+		return FormatCSV
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+	return ""
+}
+
+// open opens the seed file from FS, or from the OS file system when FS is nil.
+func (s *Seed) open() (io.ReadCloser, error) {
+	if s.FS == nil {
+		return os.Open(s.Path)
+	}
+	return s.FS.Open(s.Path)
+}
+
+// read returns the whole content of the seed file.
+func (s *Seed) read() ([]byte, error) {
+	file, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// readCSV streams the records of a CSV file with a header row.
+func readCSV(r io.Reader, add func([]string, map[string]any) error) error {
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for index := range header {
+		header[index] = strings.TrimSpace(header[index])
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		values := make(map[string]any, len(header))
+		for index, column := range header {
+			values[column] = record[index]
+		}
+		if err := add(header, values); err != nil {
+			return err
+		}
+	}
+}
+
+// readJSON streams the objects of a JSON array.
+func readJSON(r io.Reader, add func([]string, map[string]any) error) error {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("%w: JSON seed must be an array of objects", ErrInvalidSource)
+	}
+
+	for decoder.More() {
+		var values map[string]any
+		if err := decoder.Decode(&values); err != nil {
+			return err
+		}
+		if err := add(keys(values), values); err != nil {
+			return err
+		}
+	}
+
+	_, err = decoder.Token()
+	return err
+}
+
+// readYAML decodes the mappings of a YAML sequence.
+func readYAML(r io.Reader, add func([]string, map[string]any) error) error {
+	var rows []map[string]any
+	if err := yaml.NewDecoder(r).Decode(&rows); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	for _, values := range rows {
+		if err := add(keys(values), values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keys returns the keys of a decoded object in alphabetical order.
+func keys(values map[string]any) []string {
+	result := make([]string, 0, len(values))
+	for key := range values {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// convertSeed converts a decoded seed value to the given column type.
+func convertSeed(value any, kind ColumnType) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	if text, ok := value.(string); ok && text == "" && kind != TypeString {
+		return nil, nil
+	}
+
+	switch kind {
+	case TypeString:
+		if number, ok := value.(json.Number); ok {
+			return number.String(), nil
+		}
+		return fmt.Sprint(value), nil
+
+	case TypeInt:
+		switch v := value.(type) {
+		case string:
+			return strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		case json.Number:
+			return v.Int64()
+		case int:
+			return int64(v), nil
+		case float64:
+			if v != math.Trunc(v) {
+				return nil, fmt.Errorf("%v is not an integer", v)
+			}
+			return int64(v), nil
+		}
+
+	case TypeFloat:
+		switch v := value.(type) {
+		case string:
+			return strconv.ParseFloat(strings.TrimSpace(v), 64)
+		case json.Number:
+			return v.Float64()
+		case int:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		}
+
+	case TypeBool:
+		switch v := value.(type) {
+		case string:
+			return strconv.ParseBool(strings.TrimSpace(v))
+		case bool:
+			return v, nil
+		}
+
+	case TypeTime:
+		switch v := value.(type) {
+		case string:
+			text := strings.TrimSpace(v)
+			if t, err := time.Parse(time.RFC3339Nano, text); err == nil {
+				return t, nil
+			}
+			return time.Parse(time.DateOnly, text)
+		case time.Time:
+			return v, nil
+		}
+
+	case TypeJSON:
+		if text, ok := value.(string); ok {
+			if !json.Valid([]byte(text)) {
+				return nil, fmt.Errorf("invalid JSON %q", text)
+			}
+			return json.RawMessage(text), nil
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(data), nil
+
+	default:
+		if number, ok := value.(json.Number); ok {
+			if integer, err := number.Int64(); err == nil {
+				return integer, nil
+			}
+			return number.Float64()
+		}
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("cannot convert %T to the column type", value)
+}
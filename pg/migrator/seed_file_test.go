@@ -0,0 +1,167 @@
+package migrator
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedFS holds the same catalog encoded in every supported format.
+var seedFS = fstest.MapFS{
+	"seed/countries.csv": {Data: []byte("code,name,population,active\nEC,Ecuador,18000000,true\nPE,Perú,,false\nCO,Colombia,52000000,true\n")},
+	"seed/countries.json": {Data: []byte(`[
+		{"code": "EC", "name": "Ecuador", "population": 18000000, "active": true},
+		{"code": "PE", "name": "Perú", "active": false},
+		{"code": "CO", "name": "Colombia", "population": 52000000, "active": true}
+	]`)},
+	"seed/countries.yaml": {Data: []byte(`
+- code: EC
+  name: Ecuador
+  population: 18000000
+  active: true
+- code: PE
+  name: Perú
+  active: false
+- code: CO
+  name: Colombia
+  population: 52000000
+  active: true
+`)},
+}
+
+// TestSeedEach tests reading seed files in batches with typed columns.
+func TestSeedEach(t *testing.T) {
+	expected := [][]any{
+		{"EC", "Ecuador", int64(18000000), true},
+		{"PE", "Perú", nil, false},
+		{"CO", "Colombia", int64(52000000), true},
+	}
+
+	for _, file := range []string{"seed/countries.csv", "seed/countries.json", "seed/countries.yaml"} {
+		t.Run(file, func(t *testing.T) {
+			seed := &Seed{
+				Table:   "country",
+				Check:   []string{"code"},
+				Columns: []string{"code", "name", "population", "active"},
+				Types:   map[string]ColumnType{"population": TypeInt, "active": TypeBool},
+				FS:      seedFS,
+				Path:    file,
+				Batch:   2,
+			}
+
+			var batches []*Entity
+			require.NoError(t, seed.Each(func(entity *Entity) error {
+				batches = append(batches, entity)
+				return nil
+			}))
+
+			require.Len(t, batches, 2)
+			assert.Equal(t, expected[:2], batches[0].Values)
+			assert.Equal(t, expected[2:], batches[1].Values)
+			assert.Equal(t, "country", batches[0].Table)
+			assert.Equal(t, []string{"code"}, batches[0].Check)
+		})
+	}
+}
+
+// TestSeedColumnsFromFile tests that the columns default to the ones found in the file.
+func TestSeedColumnsFromFile(t *testing.T) {
+	seed := &Seed{Table: "country", Check: []string{"code"}, FS: seedFS, Path: "seed/countries.csv"}
+
+	var entity *Entity
+	require.NoError(t, seed.Each(func(e *Entity) error {
+		entity = e
+		return nil
+	}))
+
+	assert.Equal(t, []string{"code", "name", "population", "active"}, entity.Columns)
+	assert.Equal(t, []any{"EC", "Ecuador", "18000000", "true"}, entity.Values[0])
+}
+
+// TestSeedKeysFromEveryObject tests that the columns of JSON and YAML files
+// include keys that only appear after the first object.
+func TestSeedKeysFromEveryObject(t *testing.T) {
+	files := fstest.MapFS{
+		"tags.json": {Data: []byte(`[{"name": "new"}, {"name": "sale", "color": "red"}, {"name": "vip", "rank": 1}]`)},
+		"tags.yaml": {Data: []byte("- name: new\n- name: sale\n  color: red\n- name: vip\n  rank: 1\n")},
+	}
+
+	for _, file := range []string{"tags.json", "tags.yaml"} {
+		t.Run(file, func(t *testing.T) {
+			seed := &Seed{Table: "tag", Check: []string{"name"}, Types: map[string]ColumnType{"rank": TypeInt}, FS: files, Path: file, Batch: 1}
+
+			var batches []*Entity
+			require.NoError(t, seed.Each(func(entity *Entity) error {
+				batches = append(batches, entity)
+				return nil
+			}))
+
+			require.Len(t, batches, 3)
+			for _, entity := range batches {
+				assert.Equal(t, []string{"color", "name", "rank"}, entity.Columns)
+			}
+			assert.Equal(t, []any{nil, "new", nil}, batches[0].Values[0])
+			assert.Equal(t, []any{"red", "sale", nil}, batches[1].Values[0])
+			assert.Equal(t, []any{nil, "vip", int64(1)}, batches[2].Values[0])
+		})
+	}
+}
+
+// TestSeedInvalidValue tests that conversion errors identify the row and column.
+func TestSeedInvalidValue(t *testing.T) {
+	seed := &Seed{
+		Table: "country",
+		Types: map[string]ColumnType{"population": TypeInt},
+		FS:    fstest.MapFS{"countries.csv": {Data: []byte("code,population\nEC,many\n")}},
+		Path:  "countries.csv",
+	}
+
+	err := seed.Each(func(*Entity) error { return nil })
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "countries.csv row 1 column population")
+}
+
+// TestConvertSeed tests the conversion of decoded values to column types.
+func TestConvertSeed(t *testing.T) {
+	tests := []struct {
+		name     string     // name of the test case
+		value    any        // the decoded value
+		kind     ColumnType // the column type
+		expected any        // the converted value
+	}{
+		{name: "Empty cell as null", value: "", kind: TypeInt, expected: nil},
+		{name: "Empty cell as string", value: "", kind: TypeString, expected: ""},
+		{name: "Float", value: "10.5", kind: TypeFloat, expected: 10.5},
+		{name: "Date", value: "2025-01-02", kind: TypeTime, expected: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{name: "Timestamp", value: "2025-01-02T03:04:05Z", kind: TypeTime, expected: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{name: "JSON text", value: `{"a":1}`, kind: TypeJSON, expected: []byte(`{"a":1}`)},
+		{name: "JSON object", value: map[string]any{"a": 1}, kind: TypeJSON, expected: []byte(`{"a":1}`)},
+		{name: "Number as string", value: 593, kind: TypeString, expected: "593"},
+	}
+
+	for _, item := range tests {
+		t.Run(item.name, func(t *testing.T) {
+			value, err := convertSeed(item.value, item.kind)
+			require.NoError(t, err)
+			if expected, ok := item.expected.([]byte); ok {
+				assert.JSONEq(t, string(expected), string(value.(json.RawMessage)))
+				return
+			}
+			assert.Equal(t, item.expected, value)
+		})
+	}
+}
+
+// TestSeedChecksum tests that the checksum follows the content of the seed file.
+func TestSeedChecksum(t *testing.T) {
+	fsys := fstest.MapFS{"countries.csv": {Data: []byte("code\nEC\n")}}
+	seed := &Seed{Table: "country", FS: fsys, Path: "countries.csv"}
+	before := seed.checksum()
+
+	fsys["countries.csv"] = &fstest.MapFile{Data: []byte("code\nEC\nPE\n")}
+	assert.NotEqual(t, before, seed.checksum())
+}
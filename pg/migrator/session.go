@@ -0,0 +1,86 @@
+package migrator
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// path returns the search_path used while migrating. When a target schema is
+// configured it comes first, followed by the configured search path, or by
+// "public" so that shared extensions and types remain visible.
+func (m *migrator) path() []string {
+	if m.dbSchema == "" {
+		return m.searchPath
+	}
+
+	path := []string{m.dbSchema}
+	if len(m.searchPath) == 0 {
+		return append(path, "public")
+	}
+	for _, schema := range m.searchPath {
+		if schema != m.dbSchema {
+			path = append(path, schema)
+		}
+	}
+	return path
+}
+
+// connected runs fn on a single dedicated connection taken from the pool,
+// which the migrator uses for every statement until fn returns. The
+// connection's search_path is set for the duration of fn, then reset before
+// the connection returns to the pool.
+//
+// A dedicated connection is only taken when it is needed, that is, when the
+// advisory lock is enabled or a search_path must be set. The schemas of the
+// search_path are validated first and quoted, so they are kept as written.
+//
+// Parameters:
+//   - fn: the operation to run on the connection
+//
+// Returns:
+//   - error: ErrInvalidSchema for an invalid schema name, or if the connection
+//     cannot be configured or fn fails
+func (m *migrator) connected(fn func() error) error {
+	path := m.path()
+	quoted := make([]string, len(path))
+	for i, schema := range path {
+		if err := checkSchema(schema); err != nil {
+			return err
+		}
+		quoted[i] = quoteIdent(schema)
+	}
+
+	if m.lock.disabled && len(path) == 0 {
+		return fn()
+	}
+
+	return m.db.Connection(func(conn *gorm.DB) (err error) {
+		if len(path) > 0 {
+			if err := conn.Exec("SET search_path TO " + strings.Join(quoted, ", ")).Error; err != nil {
+				return err
+			}
+			defer func() {
+				resetErr := conn.Exec("RESET search_path").Error
+				if err == nil {
+					err = resetErr
+				}
+			}()
+		}
+
+		// Run every statement on the dedicated connection
+		db := m.db
+		m.db = conn
+		defer func() { m.db = db }()
+
+		return fn()
+	})
+}
+
+// createSchema creates the target schema when it is configured and missing.
+func (m *migrator) createSchema() error {
+	if m.dbSchema == "" {
+		return nil
+	}
+	return m.db.Exec("CREATE SCHEMA IF NOT EXISTS " + quoteIdent(m.dbSchema)).Error
+}
@@ -0,0 +1,323 @@
+package migrator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	upSuffix   = ".up.sql"   // Suffix of the files applying a SQL migration
+	downSuffix = ".down.sql" // Suffix of the files reverting a SQL migration
+)
+
+// SQLMigration defines a database migration written as plain SQL scripts,
+// usually loaded from versioned files with Load or LoadDir.
+//
+// It runs through the same tracking table and transaction handling as
+// SchemaMigration and DataMigration. With NoTransaction, the up script is
+// split into statements that run one by one outside of a transaction.
+type SQLMigration struct {
+	Code          string   // Unique code identifier for the SQLMigration (max 20 chars)
+	Name          string   // Human-readable name for the SQLMigration (max 100 chars)
+	Description   string   // Optional detailed description of the SQLMigration (max 255 chars)
+	Requires      []string // Codes of the migrations that must be applied before this one
+	Up            string   // SQL script that applies the migration
+	Down          string   // Optional SQL script that reverts the migration
+	NoTransaction bool     // Whether to run the up script statement by statement without a transaction
+}
+
+// GetCode returns the unique identifier for the migration
+func (m *SQLMigration) GetCode() string {
+	return m.Code
+}
+
+// GetName returns the human-readable name for the migration
+func (m *SQLMigration) GetName() string {
+	return m.Name
+}
+
+// GetDescription returns the detailed description for the migration
+func (m *SQLMigration) GetDescription() string {
+	return m.Description
+}
+
+// GetRequires returns the codes of the migrations that must be applied first
+func (m *SQLMigration) GetRequires() []string {
+	return m.Requires
+}
+
+// Execute runs the up script within a transaction, or statement by statement
+// when the migration opts out of it
+func (m *SQLMigration) Execute(tx *gorm.DB) error {
+	if !m.NoTransaction {
+		return tx.Exec(m.Up).Error
+	}
+	for _, statement := range splitStatements(m.Up) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InTransaction reports whether the SQL migration runs inside a transaction
+func (m *SQLMigration) InTransaction() bool {
+	return !m.NoTransaction
+}
+
+// CanRollback reports whether the SQL migration defines a down script
+func (m *SQLMigration) CanRollback() bool {
+	return strings.TrimSpace(m.Down) != ""
+}
+
+// Rollback runs the down script within a transaction
+func (m *SQLMigration) Rollback(tx *gorm.DB) error {
+	if !m.CanRollback() {
+		return rollback(tx, m.Code, nil)
+	}
+	return rollback(tx, m.Code, []string{m.Down})
+}
+
+// Checksum returns a hash of the up script
+func (m *SQLMigration) Checksum() string {
+	return checksum(m.Up)
+}
+
+// Load reads versioned SQL migrations from a directory of the given file system,
+// such as an embed.FS or the result of os.DirFS.
+//
+// Each migration is made of a file named "<code>_<name>.up.sql" and an optional
+// "<code>_<name>.down.sql" file, e.g. "0001_create_users.up.sql". The code is the
+// part before the first underscore and the name is the rest of the file name with
+// underscores replaced by spaces. Other files are ignored.
+//
+// An up script may declare the codes it depends on in a leading comment line
+// such as "-- requires: 0001, 0002", which fills the Requires field, and opt
+// out of the transaction with a leading "-- no-transaction" line.
+//
+// Migrations are returned sorted by code, so codes should be zero padded or
+// timestamps to keep their order.
+//
+// Parameters:
+//   - fsys: the file system containing the migrations
+//   - dir: the directory within fsys, "." for its root
+//
+// Returns:
+//   - []Migration: the loaded migrations, ready to be passed to AddSchema
+//   - error: if the directory cannot be read or a file name is invalid
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byCode := map[string]*SQLMigration{}
+	downs := map[string]string{}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		file := entry.Name()
+		var base string
+		switch {
+		case strings.HasSuffix(file, upSuffix):
+			base = strings.TrimSuffix(file, upSuffix)
+		case strings.HasSuffix(file, downSuffix):
+			base = strings.TrimSuffix(file, downSuffix)
+		default:
+			continue
+		}
+
+		code, name := parseFileName(base)
+		if code == "" || len(code) > 20 {
+			return nil, fmt.Errorf("%w: %s must start with a code of 1 to 20 characters", ErrInvalidSource, file)
+		}
+		if len(name) > 100 {
+			return nil, fmt.Errorf("%w: %s has a name longer than 100 characters", ErrInvalidSource, file)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasSuffix(file, downSuffix) {
+			downs[code] = string(content)
+			continue
+		}
+
+		if _, ok := byCode[code]; ok {
+			return nil, fmt.Errorf("%w: duplicated code %s", ErrInvalidSource, code)
+		}
+		byCode[code] = &SQLMigration{
+			Code:          code,
+			Name:          name,
+			Requires:      parseRequires(string(content)),
+			Up:            string(content),
+			NoTransaction: parseNoTransaction(string(content)),
+		}
+	}
+
+	for code, down := range downs {
+		migration, ok := byCode[code]
+		if !ok {
+			return nil, fmt.Errorf("%w: down script without up script for code %s", ErrInvalidSource, code)
+		}
+		migration.Down = down
+	}
+
+	migrations := make([]Migration, 0, len(byCode))
+	for _, migration := range byCode {
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].GetCode() < migrations[j].GetCode()
+	})
+
+	return migrations, nil
+}
+
+// LoadDir reads versioned SQL migrations from a directory of the operating
+// system, following the naming rules of Load.
+func LoadDir(dir string) ([]Migration, error) {
+	return Load(os.DirFS(dir), ".")
+}
+
+// parseFileName splits a migration file name, without suffix, into its code and name.
+func parseFileName(base string) (code, name string) {
+	code, name, found := strings.Cut(base, "_")
+	if !found {
+		return code, code
+	}
+	return code, strings.ReplaceAll(name, "_", " ")
+}
+
+// leadingComments returns the text of the comment lines at the top of a script,
+// stopping at the first line that is not a comment.
+func leadingComments(script string) []string {
+	var comments []string
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "--") {
+			break
+		}
+		comments = append(comments, strings.TrimSpace(strings.TrimPrefix(line, "--")))
+	}
+	return comments
+}
+
+// parseNoTransaction reports whether a script has a "-- no-transaction" comment line at its top.
+func parseNoTransaction(script string) bool {
+	for _, comment := range leadingComments(script) {
+		if strings.EqualFold(comment, "no-transaction") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRequires reads the codes listed in "-- requires:" comment lines at the
+// top of a script.
+func parseRequires(script string) []string {
+	var requires []string
+	for _, comment := range leadingComments(script) {
+		list, found := strings.CutPrefix(strings.ToLower(comment), "requires:")
+		if !found {
+			continue
+		}
+		for _, code := range strings.Split(comment[len(comment)-len(list):], ",") {
+			if code = strings.TrimSpace(code); code != "" {
+				requires = append(requires, code)
+			}
+		}
+	}
+	return requires
+}
+
+// splitStatements splits a script into its statements at the semicolons that are
+// not inside quotes, dollar-quoted bodies or comments. Empty statements are dropped.
+func splitStatements(script string) []string {
+	var statements []string
+	start := 0
+
+	for i := 0; i < len(script); i++ {
+		switch {
+		case script[i] == '\'' || script[i] == '"':
+			end := strings.IndexByte(script[i+1:], script[i])
+			if end < 0 {
+				i = len(script)
+				continue
+			}
+			i += end + 1
+		case strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				i = len(script)
+				continue
+			}
+			i += end
+		case strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+				continue
+			}
+			i += end + 3
+		case script[i] == '$':
+			tag := dollarTag(script[i:])
+			if tag == "" {
+				continue
+			}
+			end := strings.Index(script[i+len(tag):], tag)
+			if end < 0 {
+				i = len(script)
+				continue
+			}
+			i += len(tag) + end + len(tag) - 1
+		case script[i] == ';':
+			statements = appendStatement(statements, script[start:i])
+			start = i + 1
+		}
+	}
+
+	return appendStatement(statements, script[start:])
+}
+
+// dollarTag returns the dollar quote tag at the start of s, e.g. "$$" or "$body$",
+// or an empty string if s does not start with one.
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '$':
+			return s[:i+1]
+		case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 1 && c >= '0' && c <= '9'):
+			continue
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// appendStatement appends a statement unless it only contains whitespace and comments.
+func appendStatement(statements []string, statement string) []string {
+	statement = strings.TrimSpace(statement)
+	for _, line := range strings.Split(statement, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+			return append(statements, statement)
+		}
+	}
+	return statements
+}
@@ -0,0 +1,146 @@
+package migrator
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoad tests loading SQL migrations from a file system.
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0002_add_roles.up.sql":      {Data: []byte("CREATE TABLE roles (id bigserial);")},
+		"migrations/0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id bigserial);")},
+		"migrations/0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"migrations/README.md":                  {Data: []byte("ignored")},
+	}
+
+	migrations, err := Load(fsys, "migrations")
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+
+	first := migrations[0].(*SQLMigration)
+	assert.Equal(t, "0001", first.Code)
+	assert.Equal(t, "create users", first.Name)
+	assert.Equal(t, "CREATE TABLE users (id bigserial);", first.Up)
+	assert.Equal(t, "DROP TABLE users;", first.Down)
+	assert.True(t, first.CanRollback())
+
+	second := migrations[1].(*SQLMigration)
+	assert.Equal(t, "0002", second.Code)
+	assert.Equal(t, "add roles", second.Name)
+	assert.False(t, second.CanRollback())
+}
+
+// TestLoadInvalid tests that invalid migration files are reported.
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name string       // name of the test case
+		fsys fstest.MapFS // the file system to load
+	}{
+		{
+			name: "Down script without up script",
+			fsys: fstest.MapFS{"0001_users.down.sql": {Data: []byte("DROP TABLE users;")}},
+		},
+		{
+			name: "Code longer than 20 characters",
+			fsys: fstest.MapFS{"202610181530450000001_users.up.sql": {Data: []byte("SELECT 1;")}},
+		},
+		{
+			name: "Duplicated code",
+			fsys: fstest.MapFS{
+				"0001_users.up.sql": {Data: []byte("SELECT 1;")},
+				"0001_roles.up.sql": {Data: []byte("SELECT 1;")},
+			},
+		},
+	}
+
+	for _, item := range tests {
+		t.Run(item.name, func(t *testing.T) {
+			_, err := Load(item.fsys, ".")
+			assert.ErrorIs(t, err, ErrInvalidSource)
+		})
+	}
+}
+
+// TestParseFileName tests splitting a migration file name into code and name.
+func TestParseFileName(t *testing.T) {
+	code, name := parseFileName("0001_create_users")
+	assert.Equal(t, "0001", code)
+	assert.Equal(t, "create users", name)
+
+	code, name = parseFileName("init")
+	assert.Equal(t, "init", code)
+	assert.Equal(t, "init", name)
+}
+
+// TestParseRequires tests reading required codes from the header of a script.
+func TestParseRequires(t *testing.T) {
+	script := `-- Profiles of the users
+-- requires: 0001, 0002
+-- Requires: 0003
+
+CREATE TABLE profiles (id bigserial);
+-- requires: 0004
+`
+	assert.Equal(t, []string{"0001", "0002", "0003"}, parseRequires(script))
+	assert.Nil(t, parseRequires("CREATE TABLE users (id bigserial);"))
+}
+
+// TestParseNoTransaction tests reading the no-transaction directive from the header of a script.
+func TestParseNoTransaction(t *testing.T) {
+	assert.True(t, parseNoTransaction("-- requires: 0001\n-- no-transaction\nCREATE INDEX CONCURRENTLY idx ON users (name);"))
+	assert.False(t, parseNoTransaction("CREATE TABLE users (id bigserial);\n-- no-transaction"))
+
+	migrations, err := Load(fstest.MapFS{"0001_vacuum.up.sql": {Data: []byte("-- no-transaction\nVACUUM users;")}}, ".")
+	require.NoError(t, err)
+	require.Len(t, migrations, 1)
+	assert.False(t, transactional(migrations[0]))
+}
+
+// TestSplitStatements tests splitting a script into statements.
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name     string   // name of the test case
+		script   string   // the script to split
+		expected []string // the expected statements
+	}{
+		{
+			name:     "Simple statements",
+			script:   "VACUUM users;\nCREATE INDEX CONCURRENTLY idx_users_name ON users (name);\n",
+			expected: []string{"VACUUM users", "CREATE INDEX CONCURRENTLY idx_users_name ON users (name)"},
+		},
+		{
+			name:     "Semicolons in quotes and comments",
+			script:   "-- no-transaction; really\nINSERT INTO notes VALUES ('a;b', \"c;d\"); /* e; f */ SELECT 1",
+			expected: []string{"-- no-transaction; really\nINSERT INTO notes VALUES ('a;b', \"c;d\")", "/* e; f */ SELECT 1"},
+		},
+		{
+			name:     "Dollar quoted bodies",
+			script:   "DO $$ BEGIN PERFORM 1; END $$;\nCREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql;",
+			expected: []string{"DO $$ BEGIN PERFORM 1; END $$", "CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql"},
+		},
+		{
+			name:     "Only comments",
+			script:   "-- nothing to do\n;\n",
+			expected: nil,
+		},
+	}
+
+	for _, item := range tests {
+		t.Run(item.name, func(t *testing.T) {
+			assert.Equal(t, item.expected, splitStatements(item.script))
+		})
+	}
+}
+
+// TestNoTransactionExecute tests that a non-transactional SQL migration runs statement by statement.
+func TestNoTransactionExecute(t *testing.T) {
+	migration := &SQLMigration{Code: "0001", Up: "VACUUM users;\nVACUUM roles;", NoTransaction: true}
+
+	rec := newRecorder()
+	require.NoError(t, migration.Execute(dryRun(t, rec)))
+	assert.Equal(t, []string{"VACUUM users", "VACUUM roles"}, rec.statements)
+}
@@ -0,0 +1,160 @@
+package migrator
+
+import (
+	"log"
+	"time"
+
+	"github.com/pinzlab/goutil/terminal"
+)
+
+// State describes how a migration relates to the tracking table.
+type State string
+
+const (
+	// StateApplied marks a registered migration recorded in the tracking table.
+	StateApplied State = "Applied"
+	// StatePending marks a registered migration not yet recorded in the tracking table.
+	StatePending State = "Pending"
+	// StateInProgress marks a registered batched migration that started but has not finished yet.
+	StateInProgress State = "In progress"
+	// StateUnknown marks a migration recorded in the tracking table that is no longer registered.
+	StateUnknown State = "Unknown"
+)
+
+// MigrationStatus describes the state of a single migration.
+type MigrationStatus struct {
+	Code        string     // Unique code identifier of the migration
+	Name        string     // Human-readable name of the migration
+	Description string     // Description recorded in the tracking table, if applied
+	State       State      // Whether the migration is applied, pending or unknown
+	AppliedAt   *time.Time // Timestamp of when the migration was applied, nil if pending
+	Cursor      string     // Position reached by a batched migration in progress
+}
+
+// Status is the list of migration states returned by the migrator's Status method.
+type Status []MigrationStatus
+
+// Status compares the registered migrations against the tracking table.
+// Registered migrations are listed first, in the order Run applies them, as applied
+// or pending. Migrations recorded in the tracking table that are no longer
+// registered follow as unknown, ordered by the time they were applied.
+//
+// The tracking table is not created when it does not exist; in that case
+// every registered migration is reported as pending.
+//
+// Returns:
+//   - Status: the state of every known migration
+//   - error: if the tracking table cannot be queried
+func (m *migrator) Status() (status Status, err error) {
+	err = m.connected(func() error {
+		status, err = m.status()
+		return err
+	})
+	return status, err
+}
+
+// status builds the status report, see Status.
+func (m *migrator) status() (Status, error) {
+	te, err := m.trackerExists()
+	if err != nil {
+		return nil, err
+	}
+
+	var records []tracker
+	if te {
+		if err := m.track(m.db).Order("cat, code").Find(&records).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	recorded := make(map[string]tracker, len(records))
+	for _, record := range records {
+		recorded[record.Code] = record
+	}
+
+	migrations, err := m.ordered()
+	if err != nil {
+		return nil, err
+	}
+
+	status := Status{}
+	registered := make(map[string]bool, len(migrations))
+	for _, migration := range migrations {
+		code := migration.GetCode()
+		registered[code] = true
+
+		item := MigrationStatus{Code: code, Name: migration.GetName(), State: StatePending}
+		if record, ok := recorded[code]; ok && record.done() {
+			appliedAt := record.CreatedAt
+			item.State = StateApplied
+			item.Description = record.Description
+			item.AppliedAt = &appliedAt
+		} else if ok {
+			item.State = StateInProgress
+			item.Cursor = *record.Cursor
+		}
+		status = append(status, item)
+	}
+
+	for _, record := range records {
+		if registered[record.Code] {
+			continue
+		}
+		appliedAt := record.CreatedAt
+		status = append(status, MigrationStatus{
+			Code:        record.Code,
+			Name:        record.Name,
+			Description: record.Description,
+			State:       StateUnknown,
+			AppliedAt:   &appliedAt,
+		})
+	}
+
+	return status, nil
+}
+
+// Pending returns the migrations that are registered but not applied.
+func (s Status) Pending() Status {
+	return s.filter(StatePending)
+}
+
+// Unknown returns the migrations that are applied but no longer registered.
+func (s Status) Unknown() Status {
+	return s.filter(StateUnknown)
+}
+
+// filter returns the migrations in the given state.
+func (s Status) filter(state State) Status {
+	result := Status{}
+	for _, item := range s {
+		if item.State == state {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Print writes the status to the log through the terminal package,
+// labeling each migration with its state.
+func (s Status) Print() {
+	for _, item := range s {
+		msg := item.Code + " " + item.Name
+		if item.AppliedAt != nil {
+			msg += " (" + item.AppliedAt.Format(time.RFC3339) + ")"
+		}
+		if item.State == StateInProgress {
+			msg += " (cursor " + item.Cursor + ")"
+		}
+
+		switch item.State {
+		case StateApplied:
+			log.Println(terminal.Alert(terminal.BgGreen, string(item.State), msg))
+		case StatePending:
+			log.Println(terminal.Alert(terminal.BgYellow, string(item.State), msg))
+		case StateInProgress:
+			log.Println(terminal.Alert(terminal.BgMagenta, string(item.State), msg))
+		default:
+			log.Println(terminal.Alert(terminal.BgRed, string(item.State), msg))
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package migrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStatusFilter tests the selection of pending and unknown migrations from a status report.
+func TestStatusFilter(t *testing.T) {
+	appliedAt := time.Now()
+	status := Status{
+		{Code: "first", State: StateApplied, AppliedAt: &appliedAt},
+		{Code: "second", State: StatePending},
+		{Code: "third", State: StatePending},
+		{Code: "legacy", State: StateUnknown, AppliedAt: &appliedAt},
+	}
+
+	tests := []struct {
+		name     string   // name of the test case
+		result   Status   // the filtered status
+		expected []string // the expected migration codes
+	}{
+		{name: "Pending migrations", result: status.Pending(), expected: []string{"second", "third"}},
+		{name: "Unknown migrations", result: status.Unknown(), expected: []string{"legacy"}},
+		{name: "No unknown migrations", result: status[:3].Unknown(), expected: []string{}},
+	}
+
+	for _, item := range tests {
+		t.Run(item.name, func(t *testing.T) {
+			codes := []string{}
+			for _, migration := range item.result {
+				codes = append(codes, migration.Code)
+			}
+			assert.Equal(t, item.expected, codes)
+		})
+	}
+}
@@ -21,6 +21,17 @@ type tracker struct {
 	// Description is an field that provides more details about the migration.
 	// It can be null and is limited to 255 characters.
 	Description string `gorm:"type:varchar(255);not null"`
+
+	// Checksum is the SHA-256 hash returned by the Checksum method of the migration when it
+	// was applied: of the SQL written by hand for SQL and backfill migrations, and of the
+	// declared builders, dependencies and procedures for schema and data migrations, not of
+	// the SQL generated from them. It is empty for migrations that do not provide one or
+	// were applied before it existed.
+	Checksum string `gorm:"type:varchar(64);not null;default:''"`
+
+	// Cursor is the position reached by a batched migration that has not finished yet.
+	// It is NULL for applied migrations, so a record with a cursor is still pending.
+	Cursor *string `gorm:"column:cursor;type:text"`
 }
 
 // TableName overrides the default GORM table name for the tracker struct.
@@ -28,3 +39,22 @@ type tracker struct {
 func (*tracker) TableName() string {
 	return "migrations"
 }
+
+// done reports whether the record belongs to an applied migration,
+// rather than to a batched migration still in progress.
+func (t *tracker) done() bool {
+	return t.Cursor == nil
+}
+
+// newTracker builds the tracking record for an applied migration, including
+// its description and checksum when the migration provides them.
+func newTracker(migration Migration) *tracker {
+	record := &tracker{Code: migration.GetCode(), Name: migration.GetName()}
+	if d, ok := migration.(describer); ok {
+		record.Description = d.GetDescription()
+	}
+	if v, ok := migration.(Verifiable); ok {
+		record.Checksum = v.Checksum()
+	}
+	return record
+}
@@ -2,19 +2,88 @@ package migrator
 
 import "strings"
 
+// SoftDelete tells how a unique index handles logically deleted records.
+type SoftDelete int
+
+const (
+	SoftDeleteColumn SoftDelete = iota // Index only the records that are not logically deleted (dat IS NULL)
+	SoftDeleteNone                     // Index every record, for tables without logical deletion
+	SoftDeleteDetect                   // Use dat IS NULL only if the table has a dat column
+)
+
+// deletedColumn is the column written by track.Delete when a record is logically deleted.
+const deletedColumn = "dat"
+
 // Unique represents a unique index on a table with specific columns.
-// The index is applied only to records that are not logically deleted (WHERE dat IS NULL).
+// By default the index is applied only to records that are not logically deleted (WHERE dat IS NULL),
+// see SoftDelete for tables without logical deletion.
 type Unique struct {
-	Table   string   // Name of the table
-	Columns []string // List of columns to apply the unique constraint
+	Table            string     // Name of the table
+	Columns          []string   // List of columns or expressions to apply the unique constraint, e.g. "lower(email)"
+	Schema           string     // Optional schema of the table, resolved through search_path when empty
+	Name             string     // Optional name of the index, uni_<table>_<columns> when empty
+	SoftDelete       SoftDelete // How logically deleted records are handled, SoftDeleteColumn by default
+	Where            string     // Optional predicate of a partial index, combined with the soft delete one
+	Include          []string   // Optional non-key columns stored in the index
+	NullsNotDistinct bool       // Whether NULL values are considered equal, requires PostgreSQL 15 or later
 }
 
 // GetScript generates the SQL script to create a unique index for the table and columns.
-// The index is applied only to records where the 'dat' column is NULL, indicating the record is not logically deleted.
+// By default the index is applied only to records where the 'dat' column is NULL, indicating the record is not logically deleted.
+// With SoftDeleteDetect the script checks the table for the column in a DO block.
 func (u *Unique) GetScript() string {
+	if u.SoftDelete != SoftDeleteDetect {
+		return `
+	` + u.create(u.SoftDelete == SoftDeleteColumn) + `
+	`
+	}
+
 	return `
-	CREATE UNIQUE INDEX IF NOT EXISTS uni_` + u.Table + `_` + strings.Join(u.Columns, "_") + `
-		ON public.` + u.Table + `(` + strings.Join(u.Columns, ", ") + `)
-	WHERE dat IS NULL;
+	DO $$ BEGIN
+		IF EXISTS (SELECT 1 FROM pg_attribute WHERE attrelid = '` + qualify(u.Schema, u.Table) + `'::regclass AND attname = '` + deletedColumn + `' AND NOT attisdropped) THEN
+	` + u.create(true) + `
+		ELSE
+	` + u.create(false) + `
+		END IF;
+	END $$;
 	`
 }
+
+// name returns the name of the index.
+func (u *Unique) name() string {
+	if u.Name != "" {
+		return u.Name
+	}
+	return identifier(append([]string{"uni", u.Table}, u.Columns...)...)
+}
+
+// create returns the CREATE UNIQUE INDEX statement, restricted to records that are not
+// logically deleted when deleted is true.
+func (u *Unique) create(deleted bool) string {
+	statement := `CREATE UNIQUE INDEX IF NOT EXISTS ` + u.name() + `
+		ON ` + qualify(u.Schema, u.Table) + `(` + strings.Join(u.Columns, ", ") + `)`
+	if len(u.Include) > 0 {
+		statement += " INCLUDE (" + strings.Join(u.Include, ", ") + ")"
+	}
+	if u.NullsNotDistinct {
+		statement += " NULLS NOT DISTINCT"
+	}
+
+	var predicates []string
+	if deleted {
+		predicates = append(predicates, deletedColumn+" IS NULL")
+	}
+	if u.Where != "" {
+		if deleted {
+			predicates = append(predicates, "("+u.Where+")")
+		} else {
+			predicates = append(predicates, u.Where)
+		}
+	}
+	if len(predicates) > 0 {
+		statement += `
+	WHERE ` + strings.Join(predicates, " AND ")
+	}
+
+	return statement + ";"
+}
@@ -23,7 +23,7 @@ func TestGetUniqueScript(t *testing.T) {
 			},
 			expected: `
 	CREATE UNIQUE INDEX IF NOT EXISTS uni_users_email
-		ON public.users(email)
+		ON users(email)
 	WHERE dat IS NULL;
 	`,
 		},
@@ -35,7 +35,7 @@ func TestGetUniqueScript(t *testing.T) {
 			},
 			expected: `
 	CREATE UNIQUE INDEX IF NOT EXISTS uni_orders_user_id_order_date
-		ON public.orders(user_id, order_date)
+		ON orders(user_id, order_date)
 	WHERE dat IS NULL;
 	`,
 		},
@@ -47,8 +47,84 @@ func TestGetUniqueScript(t *testing.T) {
 			},
 			expected: `
 	CREATE UNIQUE INDEX IF NOT EXISTS uni_products_category_sku
-		ON public.products(category, sku)
+		ON products(category, sku)
 	WHERE dat IS NULL;
+	`,
+		},
+		{
+			name: "Unique index in a schema",
+			unique: Unique{
+				Table:   "users",
+				Columns: []string{"email"},
+				Schema:  "tenant",
+			},
+			expected: `
+	CREATE UNIQUE INDEX IF NOT EXISTS uni_users_email
+		ON tenant.users(email)
+	WHERE dat IS NULL;
+	`,
+		},
+		{
+			name: "Unique index without soft delete",
+			unique: Unique{
+				Table:      "countries",
+				Columns:    []string{"code"},
+				SoftDelete: SoftDeleteNone,
+			},
+			expected: `
+	CREATE UNIQUE INDEX IF NOT EXISTS uni_countries_code
+		ON countries(code);
+	`,
+		},
+		{
+			name: "Expression column with predicate and include",
+			unique: Unique{
+				Table:   "users",
+				Columns: []string{"lower(email)"},
+				Include: []string{"name"},
+				Where:   "status = 'active'",
+			},
+			expected: `
+	CREATE UNIQUE INDEX IF NOT EXISTS uni_users_lower_email
+		ON users(lower(email)) INCLUDE (name)
+	WHERE dat IS NULL AND (status = 'active');
+	`,
+		},
+		{
+			name: "Named index with nulls not distinct",
+			unique: Unique{
+				Table:            "accounts",
+				Name:             "uni_accounts_owner",
+				Columns:          []string{"owner_id", "parent_id"},
+				SoftDelete:       SoftDeleteNone,
+				NullsNotDistinct: true,
+				Where:            "kind = 'main'",
+			},
+			expected: `
+	CREATE UNIQUE INDEX IF NOT EXISTS uni_accounts_owner
+		ON accounts(owner_id, parent_id) NULLS NOT DISTINCT
+	WHERE kind = 'main';
+	`,
+		},
+		{
+			name: "Detected soft delete",
+			unique: Unique{
+				Table:      "users",
+				Columns:    []string{"email"},
+				Schema:     "tenant",
+				SoftDelete: SoftDeleteDetect,
+			},
+			expected: `
+	DO $$ BEGIN
+		IF EXISTS (SELECT 1 FROM pg_attribute WHERE attrelid = 'tenant.users'::regclass AND attname = 'dat' AND NOT attisdropped) THEN
+	CREATE UNIQUE INDEX IF NOT EXISTS uni_users_email
+		ON tenant.users(email)
+	WHERE dat IS NULL;
+		ELSE
+	CREATE UNIQUE INDEX IF NOT EXISTS uni_users_email
+		ON tenant.users(email);
+		END IF;
+	END $$;
 	`,
 		},
 	}
@@ -0,0 +1,195 @@
+package track
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeLayouts are the layouts tried to convert strings to time.Time
+// when no other conversion is registered for the pair of types. The first one
+// also formats time.Time values as strings.
+var DefaultTimeLayouts = []string{time.RFC3339Nano, time.DateTime, time.DateOnly}
+
+// conversion converts a source value and stores it in a destination value.
+type conversion func(source, target reflect.Value) error
+
+// converters holds the conversions added with Register, by pair of types.
+var converters sync.Map
+
+// enums holds the values accepted by the types added with RegisterEnum.
+var enums sync.Map
+
+// Register adds the conversion from values of type S to type D used by
+// ToCreate, ToUpdateFor and ToOutput, e.g. to parse identifiers or decimals:
+//
+//	track.Register(uuid.Parse)
+//	track.Register(decimal.NewFromString)
+//
+// The conversion applies at every level of a mapping, behind pointers and
+// inside slices and maps, and replaces the one registered before for the pair.
+// ToUpdate ignores it, since its map has no destination type to convert to.
+func Register[S, D any](convert func(S) (D, error)) {
+	source, target := reflect.TypeFor[S](), reflect.TypeFor[D]()
+
+	converters.Store(pair{source: source, target: target}, conversion(func(sourceVal, targetVal reflect.Value) error {
+		value, err := convert(sourceVal.Interface().(S))
+		if err != nil {
+			return fmt.Errorf("converting %s to %s: %w", source, target, err)
+		}
+		targetVal.Set(reflect.ValueOf(&value).Elem())
+		return nil
+	}))
+}
+
+// RegisterEnum declares the values accepted by the enum type E. Mapping any
+// other non-empty value into E, or updating a field of type E with it, fails
+// with ErrInvalidEnum.
+//
+//	track.RegisterEnum(ProfilePersonal, ProfileAgency, ProfileDeveloper, ProfileBroker)
+func RegisterEnum[E ~string](values ...E) {
+	accepted := make(map[string]bool, len(values))
+	for _, value := range values {
+		accepted[string(value)] = true
+	}
+	enums.Store(reflect.TypeFor[E](), accepted)
+}
+
+// TimeLayouts returns a conversion from strings to time.Time that tries the
+// given layouts in order, to be added with Register:
+//
+//	track.Register(track.TimeLayouts("02/01/2006", time.RFC3339))
+//
+// Empty strings are converted to the zero time.
+func TimeLayouts(layouts ...string) func(string) (time.Time, error) {
+	return func(text string) (time.Time, error) {
+		if text == "" {
+			return time.Time{}, nil
+		}
+
+		for _, layout := range layouts {
+			if value, err := time.Parse(layout, text); err == nil {
+				return value, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("%q does not match the layouts %s", text, strings.Join(layouts, ", "))
+	}
+}
+
+// lookup returns the conversion registered for the pair of types, or the
+// default conversion between strings and time.Time.
+func lookup(source, target reflect.Type) (conversion, bool) {
+	if registered, ok := converters.Load(pair{source: source, target: target}); ok {
+		return registered.(conversion), true
+	}
+
+	timeType := reflect.TypeFor[time.Time]()
+	switch {
+	case source.Kind() == reflect.String && target == timeType:
+		return parseTime, true
+	case source == timeType && target.Kind() == reflect.String:
+		return formatTime, true
+	}
+	return nil, false
+}
+
+// parseTime converts a string with one of the DefaultTimeLayouts to time.Time.
+func parseTime(source, target reflect.Value) error {
+	value, err := TimeLayouts(DefaultTimeLayouts...)(source.String())
+	if err != nil {
+		return fmt.Errorf("converting string to time: %w", err)
+	}
+	target.Set(reflect.ValueOf(value))
+	return nil
+}
+
+// formatTime converts a time.Time to a string with the first of the
+// DefaultTimeLayouts.
+func formatTime(source, target reflect.Value) error {
+	target.SetString(source.Interface().(time.Time).Format(DefaultTimeLayouts[0]))
+	return nil
+}
+
+// formatScalar returns the decimal representation of a number or a boolean.
+func formatScalar(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, value.Type().Bits())
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	}
+	return value.String()
+}
+
+// checkEnum fails when t is a registered enum that does not accept value.
+// The empty string is always accepted, to leave optional values unset.
+func checkEnum(t reflect.Type, value string) error {
+	accepted, ok := enums.Load(t)
+	if !ok || value == "" || accepted.(map[string]bool)[value] {
+		return nil
+	}
+	return fmt.Errorf("%q %w of %s", value, ErrInvalidEnum, t)
+}
+
+// nullable reports whether t has the shape of sql.Null[T] and the sql.Null*
+// types: a value field followed by a Valid boolean.
+func nullable(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.NumField() == 2 &&
+		t.Field(0).IsExported() && t.Field(1).Name == "Valid" && t.Field(1).Type.Kind() == reflect.Bool
+}
+
+// isScalar reports whether values of kind are converted from strings by convertValue.
+func isScalar(kind reflect.Kind) bool {
+	return isInteger(kind) || kind == reflect.Float32 || kind == reflect.Float64 || kind == reflect.Bool || kind == reflect.String
+}
+
+// isInteger reports whether kind is a signed or unsigned integer of any width.
+func isInteger(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// convertInteger stores an integer in an integer of another width or
+// signedness, failing when it does not fit.
+func convertInteger(source, target reflect.Value) error {
+	signed := func(kind reflect.Kind) bool { return kind >= reflect.Int && kind <= reflect.Int64 }
+
+	switch {
+	case signed(source.Kind()) && signed(target.Kind()):
+		if target.OverflowInt(source.Int()) {
+			return fmt.Errorf("%d overflows %s", source.Int(), target.Type())
+		}
+		target.SetInt(source.Int())
+
+	case signed(source.Kind()):
+		if source.Int() < 0 || target.OverflowUint(uint64(source.Int())) {
+			return fmt.Errorf("%d overflows %s", source.Int(), target.Type())
+		}
+		target.SetUint(uint64(source.Int()))
+
+	case signed(target.Kind()):
+		if source.Uint() > 1<<63-1 || target.OverflowInt(int64(source.Uint())) {
+			return fmt.Errorf("%d overflows %s", source.Uint(), target.Type())
+		}
+		target.SetInt(int64(source.Uint()))
+
+	default:
+		if target.OverflowUint(source.Uint()) {
+			return fmt.Errorf("%d overflows %s", source.Uint(), target.Type())
+		}
+		target.SetUint(source.Uint())
+	}
+
+	return nil
+}
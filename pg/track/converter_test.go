@@ -0,0 +1,149 @@
+package track
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pinzlab/goutil/internal/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Cents int64
+type LocalDate string
+type Level string
+type GQLLevel string
+
+const (
+	LevelLow     Level    = "Low"
+	LevelHigh    Level    = "High"
+	GQLLevelLow  GQLLevel = "Low"
+	GQLLevelNone GQLLevel = "None"
+)
+
+type ConvertInput struct {
+	Price    string
+	Prices   []string
+	Born     LocalDate
+	SeenAt   string
+	Nickname *string
+	ScoreID  string
+	Count    int64
+	Age      int
+	Weight   uint8
+	Level    GQLLevel
+	Deleted  sql.NullTime
+}
+
+type ConvertEntity struct {
+	Price    Cents
+	Prices   []Cents
+	Born     time.Time
+	SeenAt   *time.Time
+	Nickname sql.NullString
+	ScoreID  sql.Null[int64]
+	Count    int32
+	Age      uint
+	Weight   int64
+	Level    Level
+	Deleted  *time.Time
+}
+
+// parseCents converts a decimal amount such as "12.34" to cents.
+func parseCents(text string) (Cents, error) {
+	units, decimals, _ := strings.Cut(text, ".")
+	value, err := strconv.ParseInt(units+(decimals + "00")[:2], 10, 64)
+	return Cents(value), err
+}
+
+// TestConverters tests the registered conversions, time layouts, sql.Null* values, integer widths and enums.
+func TestConverters(t *testing.T) {
+	Register(parseCents)
+	Register(func(date LocalDate) (time.Time, error) {
+		return TimeLayouts("02/01/2006")(string(date))
+	})
+	RegisterEnum(LevelLow, LevelHigh)
+
+	deleted := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string        // Test case name
+		input    ConvertInput  // Source struct
+		expected ConvertEntity // Expected destination struct
+		fields   []string      // Paths expected in the FieldErrors
+	}{
+		{
+			name: "Successful conversions",
+			input: ConvertInput{
+				Price:    "12.34",
+				Prices:   []string{"1", "2.5"},
+				Born:     "31/12/1990",
+				SeenAt:   "2025-01-02",
+				Nickname: helper.Pointer("al"),
+				ScoreID:  "7",
+				Count:    5,
+				Age:      30,
+				Weight:   70,
+				Level:    GQLLevelLow,
+				Deleted:  sql.NullTime{Time: deleted, Valid: true},
+			},
+			expected: ConvertEntity{
+				Price:    1234,
+				Prices:   []Cents{100, 250},
+				Born:     time.Date(1990, 12, 31, 0, 0, 0, 0, time.UTC),
+				SeenAt:   &deleted,
+				Nickname: sql.NullString{String: "al", Valid: true},
+				ScoreID:  sql.Null[int64]{V: 7, Valid: true},
+				Count:    5,
+				Age:      30,
+				Weight:   70,
+				Level:    LevelLow,
+				Deleted:  &deleted,
+			},
+		},
+		{
+			name:     "Empty and invalid values",
+			input:    ConvertInput{Price: "x", Born: "1990-12-31", SeenAt: "yesterday", Count: 1 << 40, Age: -1, Level: GQLLevelNone},
+			expected: ConvertEntity{},
+			fields:   []string{"Price", "Born", "SeenAt", "Count", "Age", "Level"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var entity ConvertEntity
+
+			err := ToCreateE(&test.input, &entity, nil)
+
+			if test.fields == nil {
+				assert.NoError(t, err)
+			} else {
+				var fields FieldErrors
+				require.ErrorAs(t, err, &fields)
+				names := make([]string, len(fields))
+				for i, field := range fields {
+					names[i] = field.Field
+				}
+				assert.Equal(t, test.fields, names)
+				assert.ErrorIs(t, err, ErrInvalidEnum)
+			}
+			assert.Equal(t, test.expected, entity)
+		})
+	}
+
+	t.Run("Enums in updates", func(t *testing.T) {
+		type LevelUpdate struct {
+			Level *Level
+		}
+
+		_, err := ToUpdateE(&LevelUpdate{Level: helper.Pointer(Level("None"))}, nil)
+		assert.ErrorIs(t, err, ErrInvalidEnum)
+
+		updates, err := ToUpdateE(&LevelUpdate{Level: helper.Pointer(LevelHigh)}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, helper.Pointer(LevelHigh), updates["Level"])
+	})
+}
@@ -0,0 +1,68 @@
+package track
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrNotStruct      = errors.New("must be a pointer to a struct") // A value given to map is not a pointer to a struct
+	ErrNotSlice       = errors.New("must be a slice of structs")    // A value given to map many is not a slice of structs
+	ErrInvalidMapping = errors.New("invalid track mapping")         // The track tags of the mapped types conflict
+	ErrRequired       = errors.New("is required")                   // A required field has a zero or nil value
+	ErrInvalidEnum    = errors.New("is not a valid value")          // A value is not accepted by its enum type, see RegisterEnum
+)
+
+// FieldError describes a field that could not be mapped.
+type FieldError struct {
+	Field string // Name of the source field
+	Err   error  // Reason of the failure, ErrRequired, ErrInvalidEnum or a conversion error
+}
+
+// Error returns the field name followed by the reason of the failure.
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+// Unwrap returns the reason of the failure.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors lists every field that could not be mapped, so an API can report
+// them all at once. It can be retrieved with errors.As:
+//
+//	var fields track.FieldErrors
+//	if errors.As(err, &fields) {
+//		for _, field := range fields {
+//			fmt.Println(field.Field, field.Err)
+//		}
+//	}
+type FieldErrors []*FieldError
+
+// Error returns the errors of every field separated by semicolons.
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the errors of every field, so errors.Is and errors.As can
+// match them individually.
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// err returns the list as an error, or nil when it is empty.
+func (e FieldErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,163 @@
+package track
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// mapping copies a source value into a destination value, descending into
+// nested structs, slices and maps, and collects the fields that fail.
+type mapping struct {
+	errs FieldErrors // Fields that could not be mapped, named by their path
+}
+
+// fail records the error of the field at path.
+func (m *mapping) fail(path string, err error) {
+	m.errs = append(m.errs, &FieldError{Field: path, Err: err})
+}
+
+// structs copies the fields of source into target following their plan.
+func (m *mapping) structs(path string, source, target reflect.Value, p *plan) {
+	for _, s := range p.steps {
+		name := s.source.name
+		if path != "" {
+			name = path + "." + name
+		}
+
+		sourceFieldVal := source.FieldByIndex(s.source.index)
+		if s.required && sourceFieldVal.IsZero() {
+			m.fail(name, ErrRequired)
+			continue
+		}
+
+		m.assign(name, sourceFieldVal, target.FieldByIndex(s.target.index))
+	}
+}
+
+// assign copies a source value into a destination value. Nil pointers, slices
+// and maps are skipped, pointers are copied or dereferenced, and values are
+// stored behind a new pointer when the destination is one. Registered
+// conversions come first, and sql.Null* values are wrapped and unwrapped.
+func (m *mapping) assign(path string, source, target reflect.Value) {
+	if source.Kind() == reflect.Ptr {
+		if source.IsNil() {
+			return
+		}
+		if source.Type().AssignableTo(target.Type()) {
+			target.Set(source)
+			return
+		}
+		source = source.Elem()
+	}
+
+	if convert, ok := lookup(source.Type(), target.Type()); ok {
+		if err := convert(source, target); err != nil {
+			m.fail(path, err)
+		}
+		return
+	}
+
+	if source.Type().AssignableTo(target.Type()) {
+		if target.Kind() == reflect.String {
+			if err := checkEnum(target.Type(), source.String()); err != nil {
+				m.fail(path, err)
+				return
+			}
+		}
+		target.Set(source)
+		return
+	}
+
+	// Unwrap sql.Null* values, leaving the destination unset when they are not valid
+	if nullable(source.Type()) {
+		if source.Field(1).Bool() {
+			m.assign(path, source.Field(0), target)
+		}
+		return
+	}
+
+	if target.Kind() == reflect.Ptr {
+		failed := len(m.errs)
+		value := reflect.New(target.Type().Elem())
+		m.assign(path, source, value.Elem())
+		if len(m.errs) == failed {
+			target.Set(value)
+		}
+		return
+	}
+
+	switch {
+	case nullable(target.Type()):
+		if source.Kind() == reflect.String && source.String() == "" && target.Field(0).Kind() != reflect.String {
+			return
+		}
+
+		failed := len(m.errs)
+		m.assign(path, source, target.Field(0))
+		if len(m.errs) == failed {
+			target.Field(1).SetBool(true)
+		}
+
+	case source.Kind() == reflect.Struct && target.Kind() == reflect.Struct:
+		p := planFor(source.Type(), target.Type())
+		if p.err != nil {
+			m.fail(path, p.err)
+			return
+		}
+		m.structs(path, source, target, p)
+
+	case (source.Kind() == reflect.Slice || source.Kind() == reflect.Array) && target.Kind() == reflect.Slice:
+		m.slice(path, source, target)
+
+	case source.Kind() == reflect.Map && target.Kind() == reflect.Map:
+		m.dictionary(path, source, target)
+
+	default:
+		if err := convertValue(source, target); err != nil {
+			m.fail(path, err)
+		}
+	}
+}
+
+// slice maps every element of a source slice or array into a new destination slice.
+func (m *mapping) slice(path string, source, target reflect.Value) {
+	if source.Kind() == reflect.Slice && source.IsNil() {
+		return
+	}
+
+	elements := reflect.MakeSlice(target.Type(), source.Len(), source.Len())
+	for i := 0; i < source.Len(); i++ {
+		m.assign(fmt.Sprintf("%s[%d]", path, i), source.Index(i), elements.Index(i))
+	}
+	target.Set(elements)
+}
+
+// dictionary maps every key and value of a source map into a new destination
+// map, in the order of the keys so errors are reported consistently.
+func (m *mapping) dictionary(path string, source, target reflect.Value) {
+	if source.IsNil() {
+		return
+	}
+
+	keys := source.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+
+	entries := reflect.MakeMapWithSize(target.Type(), len(keys))
+	for _, key := range keys {
+		name := fmt.Sprintf("%s[%v]", path, key)
+
+		failed := len(m.errs)
+		targetKey := reflect.New(target.Type().Key()).Elem()
+		m.assign(name, key, targetKey)
+		targetValue := reflect.New(target.Type().Elem()).Elem()
+		m.assign(name, source.MapIndex(key), targetValue)
+
+		if len(m.errs) == failed {
+			entries.SetMapIndex(targetKey, targetValue)
+		}
+	}
+	target.Set(entries)
+}
@@ -0,0 +1,219 @@
+package track
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// tagName is the struct tag that customizes how a field is mapped.
+//
+// The tag can be set on the source or on the destination struct:
+//   - `track:"Document"` matches the field as Document instead of its Go name.
+//   - `track:"-"` ignores the field.
+//   - `track:",required"` fails the mapping when the source value is zero or nil.
+//
+// Options can be combined, e.g. `track:"Document,required"`.
+//
+// The fields of embedded structs without a tag, and of the Create, Update and
+// Delete structs, are mapped as if they were declared in the outer struct,
+// which takes precedence over them.
+const tagName = "track"
+
+// field describes a struct field that takes part in a mapping.
+type field struct {
+	index    []int  // Position of the field in its struct, through embedded structs
+	depth    int    // Number of embedded structs the field is promoted from
+	name     string // Go name of the field
+	key      string // Name used to match the field, from its tag or its Go name
+	required bool   // Whether the source value must not be zero
+}
+
+// step copies one source field into one destination field.
+type step struct {
+	source   field // Field read from the source struct
+	target   field // Field written in the destination struct
+	required bool  // Whether either side marks the field as required
+}
+
+// plan lists the steps that map a source struct type onto a destination one.
+type plan struct {
+	steps []step // Fields to copy, in the order of the source struct
+	err   error  // Error in the tags of the types, reported on every use
+}
+
+// pair identifies the source and destination types of a plan.
+type pair struct {
+	source reflect.Type
+	target reflect.Type
+}
+
+// plans caches the plan of every pair of types already mapped.
+var plans sync.Map
+
+// layouts caches the fields of every struct type already turned into updates.
+var layouts sync.Map
+
+// layout holds the mapped fields of a struct type.
+type layout struct {
+	fields []field // Fields not ignored by their tag
+	err    error   // Error in the tags of the type, reported on every use
+}
+
+// planFor returns the plan that maps source onto target, computing it on the
+// first use of the pair.
+func planFor(source, target reflect.Type) *plan {
+	key := pair{source: source, target: target}
+	if cached, ok := plans.Load(key); ok {
+		return cached.(*plan)
+	}
+
+	actual, _ := plans.LoadOrStore(key, newPlan(source, target))
+	return actual.(*plan)
+}
+
+// layoutFor returns the fields of t, computing them on the first use of the type.
+func layoutFor(t reflect.Type) *layout {
+	if cached, ok := layouts.Load(t); ok {
+		return cached.(*layout)
+	}
+
+	fields, err := fields(t)
+	actual, _ := layouts.LoadOrStore(t, &layout{fields: fields, err: err})
+	return actual.(*layout)
+}
+
+// newPlan matches the fields of source and target by their keys.
+func newPlan(source, target reflect.Type) *plan {
+	targets, err := fields(target)
+	if err != nil {
+		return &plan{err: err}
+	}
+	sources, err := fields(source)
+	if err != nil {
+		return &plan{err: err}
+	}
+
+	byKey := make(map[string]field, len(targets))
+	for _, f := range targets {
+		byKey[f.key] = f
+	}
+
+	p := &plan{}
+	matched := make(map[string]bool, len(sources))
+	for _, s := range sources {
+		t, ok := byKey[s.key]
+		if !ok {
+			if s.required {
+				return &plan{err: fmt.Errorf("%w: required field %s.%s has no match in %s", ErrInvalidMapping, source, s.name, target)}
+			}
+			continue
+		}
+
+		matched[t.key] = true
+		p.steps = append(p.steps, step{source: s, target: t, required: s.required || t.required})
+	}
+
+	for _, t := range targets {
+		if t.required && !matched[t.key] {
+			return &plan{err: fmt.Errorf("%w: required field %s.%s has no match in %s", ErrInvalidMapping, target, t.name, source)}
+		}
+	}
+
+	return p
+}
+
+// fields returns the exported fields of a struct type that are not ignored by
+// their tag, including those promoted from embedded structs, failing when two
+// of them share a key at the same depth.
+func fields(t reflect.Type) ([]field, error) {
+	var result []field
+	byKey := make(map[string]int, t.NumField())
+
+	for _, f := range collect(t, nil, 0) {
+		position, exists := byKey[f.key]
+		if !exists {
+			byKey[f.key] = len(result)
+			result = append(result, f)
+			continue
+		}
+
+		other := result[position]
+		switch {
+		case other.depth < f.depth:
+			continue
+		case other.depth > f.depth:
+			result[position] = f
+		default:
+			return nil, fmt.Errorf("%w: fields %s.%s and %s.%s are both mapped as %s", ErrInvalidMapping, t, other.name, t, f.name, f.key)
+		}
+	}
+
+	return result, nil
+}
+
+// metadata holds the tracking structs whose fields are always promoted, even
+// when they are not embedded, e.g. to map CreatedAt onto an output field.
+var metadata = map[reflect.Type]bool{
+	reflect.TypeFor[Create]():     true,
+	reflect.TypeFor[CreateOnly](): true,
+	reflect.TypeFor[Update]():     true,
+	reflect.TypeFor[UpdateOnly](): true,
+	reflect.TypeFor[Delete]():     true,
+	reflect.TypeFor[DeleteOnly](): true,
+}
+
+// collect lists the fields of a struct type, descending into the embedded
+// structs and the tracking structs without a tag.
+func collect(t reflect.Type, index []int, depth int) []field {
+	var result []field
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		position := append(append([]int(nil), index...), i)
+
+		if _, tagged := sf.Tag.Lookup(tagName); !tagged && (sf.Anonymous && sf.Type.Kind() == reflect.Struct || metadata[sf.Type]) {
+			result = append(result, collect(sf.Type, position, depth+1)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		f, ok := parseField(position, sf)
+		if !ok {
+			continue
+		}
+		f.depth = depth
+		result = append(result, f)
+	}
+
+	return result
+}
+
+// parseField reads the track tag of a struct field. It returns false when
+// the field is ignored.
+func parseField(index []int, sf reflect.StructField) (field, bool) {
+	f := field{index: index, name: sf.Name, key: sf.Name}
+
+	tag, ok := sf.Tag.Lookup(tagName)
+	if !ok {
+		return f, true
+	}
+	if tag == "-" {
+		return f, false
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+	if name != "" {
+		f.key = name
+	}
+	for _, option := range strings.Split(options, ",") {
+		if strings.TrimSpace(option) == "required" {
+			f.required = true
+		}
+	}
+
+	return f, true
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
-	"strings"
 	"time"
 )
 
@@ -50,63 +49,91 @@ func injectCreatedFields(entity interface{}, createdBy *int64) {
 
 // convertValue handles the conversion of values between different types (e.g., string -> int, string -> float, etc.)
 // It attempts to convert the source field value into the destination field value type.
-func convertValue(sourceFieldVal reflect.Value, destFieldVal reflect.Value) {
+func convertValue(sourceFieldVal reflect.Value, destFieldVal reflect.Value) error {
 	if sourceFieldVal.Kind() == reflect.String {
+		text := sourceFieldVal.String()
+
+		// Leave numbers and booleans unset when the string is empty, e.g. an optional ID
+		if text == "" && destFieldVal.Kind() != reflect.String && isScalar(destFieldVal.Kind()) {
+			return nil
+		}
+
 		// Convert string to appropriate destination field type
 		switch destFieldVal.Kind() {
-		case reflect.Int64:
-			// Convert string to int64
-			intVal, err := strconv.ParseInt(sourceFieldVal.String(), 10, 64)
-			if err == nil {
-				destFieldVal.SetInt(intVal)
-			} else {
-				fmt.Println("Error converting string to int64:", err)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			intVal, err := strconv.ParseInt(text, 10, destFieldVal.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("converting string to %s: %w", destFieldVal.Kind(), err)
 			}
+			destFieldVal.SetInt(intVal)
+			return nil
 
-		case reflect.Int32:
-			// Convert string to int32
-			intVal, err := strconv.ParseInt(sourceFieldVal.String(), 10, 32)
-			if err == nil {
-				destFieldVal.SetInt(intVal)
-			} else {
-				fmt.Println("Error converting string to int32:", err)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			uintVal, err := strconv.ParseUint(text, 10, destFieldVal.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("converting string to %s: %w", destFieldVal.Kind(), err)
 			}
+			destFieldVal.SetUint(uintVal)
+			return nil
 
-		case reflect.Float64:
-			// Convert string to float64
-			floatVal, err := strconv.ParseFloat(sourceFieldVal.String(), 64)
-			if err == nil {
-				destFieldVal.SetFloat(floatVal)
-			} else {
-				fmt.Println("Error converting string to float64:", err)
+		case reflect.Float32, reflect.Float64:
+			floatVal, err := strconv.ParseFloat(text, destFieldVal.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("converting string to %s: %w", destFieldVal.Kind(), err)
 			}
+			destFieldVal.SetFloat(floatVal)
+			return nil
 
 		case reflect.Bool:
-			// Convert string to bool
-			boolVal, err := strconv.ParseBool(sourceFieldVal.String())
-			if err == nil {
-				destFieldVal.SetBool(boolVal)
-			} else {
-				fmt.Println("Error converting string to bool:", err)
+			boolVal, err := strconv.ParseBool(text)
+			if err != nil {
+				return fmt.Errorf("converting string to bool: %w", err)
 			}
+			destFieldVal.SetBool(boolVal)
+			return nil
 
-		default:
-			// Handle custom types (e.g., ProfileType)
-			if destFieldVal.Type().Kind() == reflect.String {
-				destFieldVal.SetString(sourceFieldVal.String())
+		case reflect.String:
+			// Handle custom types (e.g., ProfileType), checking the values of registered enums
+			if err := checkEnum(destFieldVal.Type(), text); err != nil {
+				return err
 			}
+			destFieldVal.SetString(text)
+			return nil
+		}
+	}
+
+	// Convert between integers of any width, checking they fit
+	if isInteger(sourceFieldVal.Kind()) && isInteger(destFieldVal.Kind()) {
+		return convertInteger(sourceFieldVal, destFieldVal)
+	}
+
+	// Format numbers and booleans as strings, e.g. an ID for an output
+	if destFieldVal.Kind() == reflect.String && isScalar(sourceFieldVal.Kind()) {
+		text := formatScalar(sourceFieldVal)
+		if err := checkEnum(destFieldVal.Type(), text); err != nil {
+			return err
 		}
-	} else {
-		// Direct assignment for non-string types
-		destFieldVal.Set(sourceFieldVal)
+		destFieldVal.SetString(text)
+		return nil
 	}
+
+	// Direct assignment for values of the same type
+	if !sourceFieldVal.Type().AssignableTo(destFieldVal.Type()) {
+		return fmt.Errorf("cannot assign %s to %s", sourceFieldVal.Type(), destFieldVal.Type())
+	}
+	destFieldVal.Set(sourceFieldVal)
+	return nil
 }
 
 // ToCreate copies data from the input struct to the target entity struct, preparing it for database creation.
 //
-// It matches fields by name and handles ID fields ending with "ID" by converting string IDs to int32 if necessary.
-// Additionally, it sets creation metadata fields `CreatedAt` and `CreatedBy` if the target entity embeds
-// the `Create` or `CreateOnly` structs.
+// Fields are matched by name, or by the name given in their `track` tag on either struct, and ID fields
+// given as strings are converted to int64. A `track:"-"` tag ignores a field and the `required` option
+// reports the field when its source value is empty. Nested structs, slices and maps are mapped
+// recursively with the same rules, and the fields of embedded structs are matched as if they were
+// declared in the outer one. Other types are converted by the conversions added with Register.
+// Additionally, it sets creation metadata fields `CreatedAt` and `CreatedBy` if the target entity
+// embeds the `Create` or `CreateOnly` structs.
 //
 // Parameters:
 //   - input: Pointer to the source struct containing data to copy.
@@ -118,74 +145,62 @@ func convertValue(sourceFieldVal reflect.Value, destFieldVal reflect.Value) {
 //   - Both input and entity must be pointers to structs.
 //   - If `createdBy` is nil, only `CreatedAt` will be set if the entity has a `CreateOnly` struct.
 //   - Fields that are pointers in the source will be dereferenced or copied appropriately.
+//   - The matching of fields is computed once per pair of types and reused afterwards.
+//
+// Example:
+//
+//	type GQLProfile struct {
+//		UserID string
+//		Doc    string `track:"Document,required"`
+//		Token  string `track:"-"`
+//	}
 func ToCreate(input, entity interface{}, createdBy *int64) {
+	if err := ToCreateE(input, entity, createdBy); err != nil {
+		fmt.Println("Error mapping fields:", err)
+	}
+}
+
+// ToCreateE works like ToCreate but reports the fields that could not be copied
+// instead of printing them, so the caller can reject the input rather than
+// persist a half-populated entity.
+//
+// Every field is attempted before returning, and the failures are reported
+// together as FieldErrors, with ErrRequired for required fields left empty
+// and the conversion error for the others. Nested fields are named by their
+// path, e.g. "Items[1].ProductID".
+//
+// Returns:
+//   - error: ErrNotStruct if input or entity is not a pointer to a struct,
+//     ErrInvalidMapping if their track tags conflict, or FieldErrors
+func ToCreateE(input, entity interface{}, createdBy *int64) error {
 	// Obtain reflect.Values for source and target
 	sourceValue := reflect.ValueOf(input)
 	targetValue := reflect.ValueOf(entity)
 
 	// Check if both source and target are pointers to structs
-	if sourceValue.Kind() != reflect.Ptr || targetValue.Kind() != reflect.Ptr {
-		fmt.Println("Both source and destination must be pointers to structs")
-		return
+	if !isStructPointer(sourceValue) || !isStructPointer(targetValue) {
+		return fmt.Errorf("source and destination %w", ErrNotStruct)
 	}
 
 	// Dereference the pointers to obtain the underlying structs
 	sourceElem := sourceValue.Elem()
 	targetElem := targetValue.Elem()
 
-	// Get the types of the source and target structs
-	sourceType := sourceElem.Type()
-	targetType := targetElem.Type()
-
-	// Iterate through fields of the source struct
-	for i := 0; i < sourceElem.NumField(); i++ {
-		sourceField := sourceType.Field(i)
-
-		// Assign ID source to ID target
-		if strings.HasSuffix(sourceField.Name, "ID") {
-			for j := 0; j < targetElem.NumField(); j++ {
-				targetField := targetType.Field(j)
-				if targetField.Name == sourceField.Name {
-					if sourceField.Type.Kind() == reflect.String && targetField.Type.Kind() == reflect.Int64 {
-						// Convert string to int64
-						sourceFieldVal := sourceElem.Field(i)
-						destFieldVal := targetElem.Field(j)
-						intVal, err := strconv.ParseInt(sourceFieldVal.Interface().(string), 10, 64) // Cambiar a int64
-						if err != nil {
-							fmt.Println("Error converting string to int64:", err)
-							continue
-						}
-						destFieldVal.SetInt(intVal)
-					}
-					break
-
-				}
-			}
-			continue
-		}
-
-		// Assign another field from source to target
-		for j := 0; j < targetElem.NumField(); j++ {
-			targetField := targetType.Field(j)
-			if sourceField.Name == targetField.Name {
-				sourceFieldVal := sourceElem.Field(i)
-				destFieldVal := targetElem.Field(j)
-				// Handle pointer types (copy or dereference)
-				if sourceFieldVal.Kind() == reflect.Ptr && !sourceFieldVal.IsNil() {
-					if destFieldVal.Kind() == reflect.Ptr {
-						destFieldVal.Set(sourceFieldVal)
-					} else {
-						destFieldVal.Set(sourceFieldVal.Elem())
-					}
-				} else if sourceFieldVal.Kind() != reflect.Ptr {
-					// Handle non-pointer types with direct assignment
-					convertValue(sourceFieldVal, destFieldVal)
-				}
-				break
-			}
-		}
+	p := planFor(sourceElem.Type(), targetElem.Type())
+	if p.err != nil {
+		return p.err
 	}
 
+	var m mapping
+	m.structs("", sourceElem, targetElem, p)
+
 	// Set CreatedAt and CreatedBy if present in the entity
 	injectCreatedFields(entity, createdBy)
+
+	return m.errs.err()
+}
+
+// isStructPointer reports whether value is a non-nil pointer to a struct.
+func isStructPointer(value reflect.Value) bool {
+	return value.Kind() == reflect.Ptr && !value.IsNil() && value.Elem().Kind() == reflect.Struct
 }
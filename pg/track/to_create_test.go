@@ -1,10 +1,12 @@
 package track
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/pinzlab/goutil/internal/helper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type ClientType string
@@ -83,3 +85,230 @@ func TestToCreate(t *testing.T) {
 		})
 	}
 }
+
+type TaggedProfile struct {
+	UserID   *string
+	Doc      string `track:"Document,required"`
+	Nickname string `track:"-"`
+	Alias    string
+}
+
+type TaggedEntity struct {
+	UserID   int64
+	Document string
+	Nickname string
+	Name     string `track:"Alias"`
+}
+
+// TestToCreateTags tests the mapping of fields renamed, ignored or required by their track tag.
+func TestToCreateTags(t *testing.T) {
+	tests := []struct {
+		name     string        // Test case name
+		input    TaggedProfile // Source struct
+		expected TaggedEntity  // Expected destination struct
+		err      string        // Expected error, empty when none
+	}{
+		{
+			name:     "Renamed, ignored and pointer ID fields",
+			input:    TaggedProfile{UserID: helper.Pointer("7"), Doc: "0604059741", Nickname: "al", Alias: "Alice"},
+			expected: TaggedEntity{UserID: 7, Document: "0604059741", Name: "Alice"},
+		},
+		{
+			name:     "Missing required field",
+			input:    TaggedProfile{Alias: "Bob"},
+			expected: TaggedEntity{Name: "Bob"},
+			err:      "Doc: is required",
+		},
+		{
+			name:     "Invalid ID",
+			input:    TaggedProfile{UserID: helper.Pointer("x"), Doc: "1"},
+			expected: TaggedEntity{Document: "1"},
+			err:      "UserID: converting string to int64",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var entity TaggedEntity
+
+			err := ToCreateE(&test.input, &entity, nil)
+
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, test.err)
+
+				var fields FieldErrors
+				require.ErrorAs(t, err, &fields)
+				assert.Len(t, fields, 1)
+			}
+			assert.Equal(t, test.expected, entity)
+		})
+	}
+}
+
+// TestPlanFor tests that plans are cached per pair of types and report invalid tags.
+func TestPlanFor(t *testing.T) {
+	type Duplicated struct {
+		Doc      string `track:"Document"`
+		Document string
+	}
+	type Unmatched struct {
+		Code string `track:",required"`
+	}
+
+	source := reflect.TypeOf(TaggedProfile{})
+	target := reflect.TypeOf(TaggedEntity{})
+	assert.Same(t, planFor(source, target), planFor(source, target))
+	assert.Len(t, planFor(source, target).steps, 3)
+
+	assert.ErrorContains(t, planFor(reflect.TypeOf(Duplicated{}), target).err, "both mapped as Document")
+	assert.ErrorContains(t, planFor(reflect.TypeOf(Unmatched{}), target).err, "has no match")
+	assert.ErrorContains(t, ToCreateE(TaggedProfile{}, &TaggedEntity{}, nil), "must be a pointer to a struct")
+}
+
+type GQLAddress struct {
+	Street string
+	CityID string
+}
+
+type Address struct {
+	Street string
+	CityID int64
+}
+
+type GQLItem struct {
+	ProductID string
+	Type      GQLClientType
+}
+
+type Item struct {
+	ProductID int64
+	Type      ClientType
+}
+
+type GQLBase struct {
+	OwnerID string
+}
+
+type Base struct {
+	OwnerID int64
+}
+
+type NestedInput struct {
+	GQLBase
+	Name    string
+	Address *GQLAddress
+	Items   []GQLItem
+	Scores  map[string]string
+}
+
+type NestedEntity struct {
+	Base
+	Create
+	Name    string
+	Address Address
+	Items   []Item
+	Scores  map[string]int64
+}
+
+// TestToCreateNested tests the recursive mapping of nested structs, slices, maps and embedded structs.
+func TestToCreateNested(t *testing.T) {
+	tests := []struct {
+		name     string       // Test case name
+		input    NestedInput  // Source struct
+		expected NestedEntity // Expected destination struct
+		fields   []string     // Paths expected in the FieldErrors
+	}{
+		{
+			name: "Nested values converted at every level",
+			input: NestedInput{
+				GQLBase: GQLBase{OwnerID: "3"},
+				Name:    "Alice",
+				Address: &GQLAddress{Street: "Main St", CityID: "9"},
+				Items:   []GQLItem{{ProductID: "1", Type: GQLClientCompany}, {ProductID: "2"}},
+				Scores:  map[string]string{"math": "10"},
+			},
+			expected: NestedEntity{
+				Base:    Base{OwnerID: 3},
+				Name:    "Alice",
+				Address: Address{Street: "Main St", CityID: 9},
+				Items:   []Item{{ProductID: 1, Type: ClientCompany}, {ProductID: 2}},
+				Scores:  map[string]int64{"math": 10},
+			},
+		},
+		{
+			name:     "Nil nested values are skipped",
+			input:    NestedInput{Name: "Bob"},
+			expected: NestedEntity{Name: "Bob"},
+		},
+		{
+			name: "Errors named by their path",
+			input: NestedInput{
+				Address: &GQLAddress{CityID: "x"},
+				Items:   []GQLItem{{ProductID: "1"}, {ProductID: "y"}},
+				Scores:  map[string]string{"art": "z", "math": "10"},
+			},
+			expected: NestedEntity{
+				Items:  []Item{{ProductID: 1}, {}},
+				Scores: map[string]int64{"math": 10},
+			},
+			fields: []string{"Address.CityID", "Items[1].ProductID", "Scores[art]"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var entity NestedEntity
+
+			err := ToCreateE(&test.input, &entity, nil)
+
+			if test.fields == nil {
+				assert.NoError(t, err)
+			} else {
+				var fields FieldErrors
+				require.ErrorAs(t, err, &fields)
+				names := make([]string, len(fields))
+				for i, field := range fields {
+					names[i] = field.Field
+				}
+				assert.Equal(t, test.fields, names)
+			}
+			assert.Equal(t, test.expected, entity)
+		})
+	}
+}
+
+// TestFields tests the promotion of the fields of embedded structs.
+func TestFields(t *testing.T) {
+	type Inner struct {
+		Name string
+		Code string
+	}
+	type Tagged struct {
+		Value string
+	}
+	type Outer struct {
+		Inner
+		Tagged `track:"Extra"`
+		Name   string
+	}
+	type Left struct{ Code string }
+	type Right struct{ Code string }
+	type Ambiguous struct {
+		Left
+		Right
+	}
+
+	fields, err := fields(reflect.TypeOf(Outer{}))
+	require.NoError(t, err)
+
+	keys := make(map[string][]int, len(fields))
+	for _, f := range fields {
+		keys[f.key] = f.index
+	}
+	assert.Equal(t, map[string][]int{"Name": {2}, "Code": {0, 1}, "Extra": {1}}, keys)
+
+	_, err = layoutFor(reflect.TypeOf(Ambiguous{})).fields, layoutFor(reflect.TypeOf(Ambiguous{})).err
+	assert.ErrorIs(t, err, ErrInvalidMapping)
+}
@@ -0,0 +1,101 @@
+package track
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ToOutput copies a database entity into an output struct, e.g. a GraphQL
+// model, the reverse of ToCreate.
+//
+// Fields are matched with the same `track` tags as ToCreate and converted
+// with the same rules: integer IDs are formatted as strings, string-kinded
+// enums are converted between their types, and the conversions added with
+// Register apply. The fields of the `Create`, `Update` and `Delete` structs
+// are flattened, so an output can declare CreatedAt or UpdatedBy directly.
+//
+// Parameters:
+//   - entity: The struct to copy, or a pointer to it.
+//   - output: Pointer to the destination struct.
+//
+// Returns:
+//   - error: ErrNotStruct if entity or output is not a struct as expected,
+//     ErrInvalidMapping if their track tags conflict, or FieldErrors
+//
+// Example:
+//
+//	var gql GQLProfile
+//	err := track.ToOutput(&profile, &gql)
+func ToOutput(entity, output interface{}) error {
+	sourceValue := reflect.Indirect(reflect.ValueOf(entity))
+	targetValue := reflect.ValueOf(output)
+
+	if sourceValue.Kind() != reflect.Struct || !isStructPointer(targetValue) {
+		return fmt.Errorf("entity and output %w", ErrNotStruct)
+	}
+
+	p := planFor(sourceValue.Type(), targetValue.Elem().Type())
+	if p.err != nil {
+		return p.err
+	}
+
+	var m mapping
+	m.structs("", sourceValue, targetValue.Elem(), p)
+
+	return m.errs.err()
+}
+
+// ToOutputs copies a slice of database entities into a slice of output
+// structs, like ToOutput does for each of them. Failing fields are named by
+// their position, e.g. "[2].UserID".
+//
+// Parameters:
+//   - entities: A slice of structs or of pointers to structs.
+//   - outputs: Pointer to the destination slice, of structs or of pointers to structs.
+//
+// Returns:
+//   - error: ErrNotSlice if entities or the outputs pointed to are not slices of
+//     structs, ErrInvalidMapping if the track tags conflict, or FieldErrors
+//
+// Example:
+//
+//	var gql []*GQLProfile
+//	err := track.ToOutputs(profiles, &gql)
+func ToOutputs(entities, outputs interface{}) error {
+	sourceValue := reflect.Indirect(reflect.ValueOf(entities))
+	targetValue := reflect.ValueOf(outputs)
+
+	source := structElem(sourceValue)
+	if source == nil {
+		return fmt.Errorf("entities %w", ErrNotSlice)
+	}
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() || structElem(targetValue.Elem()) == nil {
+		return fmt.Errorf("outputs %w, given by a pointer", ErrNotSlice)
+	}
+
+	if p := planFor(source, structElem(targetValue.Elem())); p.err != nil {
+		return p.err
+	}
+
+	var m mapping
+	m.slice("", sourceValue, targetValue.Elem())
+
+	return m.errs.err()
+}
+
+// structElem returns the struct type of the elements of a slice of structs or
+// of pointers to structs, or nil when value is not such a slice.
+func structElem(value reflect.Value) reflect.Type {
+	if value.Kind() != reflect.Slice {
+		return nil
+	}
+
+	elem := value.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil
+	}
+	return elem
+}
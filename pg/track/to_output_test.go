@@ -0,0 +1,106 @@
+package track
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pinzlab/goutil/internal/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type Account struct {
+	Update
+	ID       int64
+	OwnerID  int64
+	Type     ClientType
+	Document string
+	Balance  float64
+	Create   Create
+	Delete   Delete
+}
+
+type GQLAccount struct {
+	ID        string
+	OwnerID   string
+	Type      GQLClientType
+	Doc       string `track:"Document"`
+	Balance   string
+	CreatedAt time.Time
+	CreatedBy string
+	UpdatedBy *string
+	DeletedAt *time.Time
+}
+
+// TestToOutput tests the reverse mapping of entities into output structs.
+func TestToOutput(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	deleted := gorm.DeletedAt{Time: created, Valid: true}
+
+	tests := []struct {
+		name     string     // Test case name
+		entity   Account    // Source entity
+		expected GQLAccount // Expected output
+	}{
+		{
+			name: "IDs, enums, renamed fields and metadata",
+			entity: Account{
+				Update:   Update{UpdatedBy: helper.Pointer[int64](8)},
+				ID:       1,
+				OwnerID:  42,
+				Type:     ClientCompany,
+				Document: "0604059741",
+				Balance:  10.5,
+				Create:   Create{CreatedAt: created, CreatedBy: 7},
+				Delete:   Delete{DeletedAt: &deleted},
+			},
+			expected: GQLAccount{
+				ID:        "1",
+				OwnerID:   "42",
+				Type:      GQLClientCompany,
+				Doc:       "0604059741",
+				Balance:   "10.5",
+				CreatedAt: created,
+				CreatedBy: "7",
+				UpdatedBy: helper.Pointer("8"),
+				DeletedAt: &created,
+			},
+		},
+		{
+			name:     "Unset metadata",
+			entity:   Account{ID: 2, Delete: Delete{DeletedAt: &gorm.DeletedAt{}}},
+			expected: GQLAccount{ID: "2", OwnerID: "0", Balance: "0", CreatedBy: "0"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var output GQLAccount
+
+			require.NoError(t, ToOutput(test.entity, &output))
+			assert.Equal(t, test.expected, output)
+		})
+	}
+}
+
+// TestToOutputs tests the reverse mapping of slices of entities.
+func TestToOutputs(t *testing.T) {
+	accounts := []*Account{{ID: 1, Type: ClientCompany}, nil, {ID: 3}}
+
+	var outputs []*GQLAccount
+	require.NoError(t, ToOutputs(accounts, &outputs))
+	require.Len(t, outputs, 3)
+	assert.Equal(t, "1", outputs[0].ID)
+	assert.Equal(t, GQLClientCompany, outputs[0].Type)
+	assert.Nil(t, outputs[1])
+	assert.Equal(t, "3", outputs[2].ID)
+
+	var values []GQLAccount
+	require.NoError(t, ToOutputs(&[]Account{{ID: 4}}, &values))
+	assert.Equal(t, "4", values[0].ID)
+
+	assert.ErrorIs(t, ToOutputs(accounts, outputs), ErrNotSlice)
+	assert.ErrorIs(t, ToOutputs(Account{}, &outputs), ErrNotSlice)
+	assert.ErrorIs(t, ToOutput(accounts[0], GQLAccount{}), ErrNotStruct)
+}
@@ -1,6 +1,7 @@
 package track
 
 import (
+	"fmt"
 	"reflect"
 	"time"
 )
@@ -8,6 +9,17 @@ import (
 // ToUpdate creates a map of updated fields from the provided data struct.
 // It captures non-nil pointer fields and includes the updater's information.
 //
+// Fields are named after their `track` tag when they have one, and a
+// `track:"-"` tag leaves a field out. Values are stored as they are, without
+// the conversions of ToCreate, since the map has no destination type: string
+// IDs such as UUIDs or external references keep their value. Use ToUpdateFor
+// to convert them to the field types of an entity. Only the values of enums
+// added with RegisterEnum are checked; the fields that fail are left out of
+// the map, use ToUpdateE to get their errors.
+//
+// ToUpdate panics when data is not a pointer to a struct, or when its track
+// tags conflict.
+//
 // Parameters:
 // - data: A pointer to the struct containing fields to check for updates.
 // - updatedBy: An optional pointer to a string indicating the user who updated the record.
@@ -20,12 +32,96 @@ import (
 // var userUpdates User
 // updates := gorm.ToUpdate(&userUpdates, updatedBy)
 func ToUpdate(data interface{}, updatedBy *int64) map[string]interface{} {
-	// Ensure data is a pointer
+	// Ensure data is a pointer to a struct
 	dataValue := reflect.ValueOf(data)
-	if dataValue.Kind() != reflect.Ptr {
+	if !isStructPointer(dataValue) {
 		panic("data must be a pointer to a struct")
 	}
 
+	updates, err := toUpdate(dataValue, nil, updatedBy)
+	if updates == nil {
+		panic(err)
+	}
+
+	return updates
+}
+
+// ToUpdateE works like ToUpdate but returns an error instead of panicking or
+// printing, so the caller can reject the input rather than apply a partial update.
+//
+// Every field is attempted before returning, and the failures are reported
+// together as FieldErrors, with ErrRequired for required fields left empty and
+// the conversion error for the others.
+//
+// Returns:
+//   - map[string]interface{}: the updates, nil when an error is returned
+//   - error: ErrNotStruct if data is not a pointer to a struct,
+//     ErrInvalidMapping if its track tags conflict, or FieldErrors
+func ToUpdateE(data interface{}, updatedBy *int64) (map[string]interface{}, error) {
+	updates, err := toUpdate(reflect.ValueOf(data), nil, updatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return updates, nil
+}
+
+// ToUpdateFor works like ToUpdateE, converting each value to the type of the
+// matching field of the entity E with the same rules as ToCreate: integer IDs
+// given as strings are parsed, the conversions added with Register apply and
+// enums are checked. Fields are matched with the same `track` tags as ToCreate
+// and stored under the Go name of the entity field; those with no match in E
+// are left out, and so are empty strings given for fields that do not hold text.
+//
+// Returns:
+//   - map[string]interface{}: the updates, nil when an error is returned
+//   - error: ErrNotStruct if data is not a pointer to a struct or E is not a
+//     struct, ErrInvalidMapping if their track tags conflict, or FieldErrors
+//
+// Example:
+//
+//	updates, err := track.ToUpdateFor[Profile](&input, &userID)
+func ToUpdateFor[E any](data interface{}, updatedBy *int64) (map[string]interface{}, error) {
+	entity := reflect.TypeFor[E]()
+	if entity.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("entity %w", ErrNotStruct)
+	}
+
+	updates, err := toUpdate(reflect.ValueOf(data), entity, updatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return updates, nil
+}
+
+// toUpdate builds the updates of dataValue, returning the fields that could be
+// mapped along with the error of those that could not. When entity is not nil,
+// the values are converted to the types of its fields.
+func toUpdate(dataValue reflect.Value, entity reflect.Type, updatedBy *int64) (map[string]interface{}, error) {
+	if !isStructPointer(dataValue) {
+		return nil, fmt.Errorf("data %w", ErrNotStruct)
+	}
+
+	dataValue = dataValue.Elem() // Dereference the pointer
+
+	var steps []step
+	if entity == nil {
+		l := layoutFor(dataValue.Type())
+		if l.err != nil {
+			return nil, l.err
+		}
+		for _, f := range l.fields {
+			steps = append(steps, step{source: f, required: f.required})
+		}
+	} else {
+		p := planFor(dataValue.Type(), entity)
+		if p.err != nil {
+			return nil, p.err
+		}
+		steps = p.steps
+	}
+
 	updates := make(map[string]interface{})
 
 	updates["UpdatedAt"] = time.Now()
@@ -33,22 +129,76 @@ func ToUpdate(data interface{}, updatedBy *int64) map[string]interface{} {
 		updates["UpdatedBy"] = updatedBy
 	}
 
-	dataValue = dataValue.Elem() // Dereference the pointer
-
-	// Iterate over the fields of the struct
-	for i := 0; i < dataValue.NumField(); i++ {
-		field := dataValue.Type().Field(i)
-		value := dataValue.Field(i)
+	// Iterate over the mapped fields of the struct
+	var m mapping
+	for _, s := range steps {
+		f := s.source
+		value := dataValue.FieldByIndex(f.index)
+		if s.required && value.IsZero() {
+			m.fail(f.name, ErrRequired)
+			continue
+		}
 
 		// Check if the field is a pointer and is not nil
-		if field.Type.Kind() == reflect.Ptr {
-			if !value.IsNil() {
-				updates[field.Name] = value.Interface()
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				continue
 			}
-		} else if field.Name != "ID" {
-			updates[field.Name] = value
+		} else if f.name == "ID" {
+			continue
+		}
+
+		if entity == nil {
+			if update, ok := m.update(f, value); ok {
+				updates[f.key] = update
+			}
+			continue
+		}
+
+		if update, ok := m.convert(f, value, entity.FieldByIndex(s.target.index).Type); ok {
+			updates[s.target.name] = update
 		}
 	}
 
-	return updates
+	return updates, m.errs.err()
+}
+
+// update returns the value stored in the updates for a field, checking the
+// values of registered enums. It returns false when the check fails.
+func (m *mapping) update(f field, value reflect.Value) (interface{}, bool) {
+	plain := reflect.Indirect(value)
+	if plain.Kind() == reflect.String {
+		if err := checkEnum(plain.Type(), plain.String()); err != nil {
+			m.fail(f.name, err)
+			return nil, false
+		}
+	}
+	return value.Interface(), true
+}
+
+// convert returns the value of a field converted to the type of the entity
+// field it updates. It returns false when the field is left out: an empty
+// string for a field that does not hold text, or a failure.
+func (m *mapping) convert(f field, value reflect.Value, target reflect.Type) (interface{}, bool) {
+	plain := reflect.Indirect(value)
+	if plain.Kind() == reflect.String && plain.String() == "" && !textual(target) {
+		return nil, false
+	}
+
+	failed := len(m.errs)
+	converted := reflect.New(target).Elem()
+	m.assign(f.name, value, converted)
+	return converted.Interface(), len(m.errs) == failed
+}
+
+// textual reports whether t holds a string, behind a pointer or a sql.Null*
+// wrapper, so an empty string is a value to store rather than a missing one.
+func textual(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if nullable(t) {
+		t = t.Field(0).Type
+	}
+	return t.Kind() == reflect.String
 }
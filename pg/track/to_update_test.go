@@ -1,10 +1,13 @@
 package track
 
 import (
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/pinzlab/goutil/internal/helper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type UpdateData struct {
@@ -59,3 +62,180 @@ func TestToUpdate(t *testing.T) {
 		})
 	}
 }
+
+// TestToUpdatePanics tests that ToUpdate panics the same way for any data that
+// is not a pointer to a struct.
+func TestToUpdatePanics(t *testing.T) {
+	name := "Alice"
+	for _, data := range []interface{}{UpdateData{}, &name, (*UpdateData)(nil), nil} {
+		assert.PanicsWithValue(t, "data must be a pointer to a struct", func() { ToUpdate(data, nil) })
+	}
+}
+
+type TaggedUpdate struct {
+	ID         int64
+	EstabID    *string
+	ExternalID *string
+	UUID       *string
+	Doc        *string `track:"Document,required"`
+	Internal   *string `track:"-"`
+	Name       string
+}
+
+// TestToUpdateE tests the updates and field errors of tagged update structs.
+func TestToUpdateE(t *testing.T) {
+	tests := []struct {
+		name     string                 // Test case name
+		data     interface{}            // Update struct
+		expected map[string]interface{} // Expected updates, without UpdatedAt
+		fields   []string               // Fields expected in the FieldErrors
+		err      error                  // Expected error, nil when none
+	}{
+		{
+			name:     "Renamed, ignored and ID fields",
+			data:     &TaggedUpdate{ID: 1, EstabID: helper.Pointer("5"), Doc: helper.Pointer("0604"), Internal: helper.Pointer("x"), Name: "Alice"},
+			expected: map[string]interface{}{"EstabID": helper.Pointer("5"), "Document": helper.Pointer("0604"), "Name": "Alice"},
+		},
+		{
+			name: "Non-numeric string IDs",
+			data: &TaggedUpdate{
+				ExternalID: helper.Pointer("cus_ABC"),
+				UUID:       helper.Pointer("0b6f7c1e-2d3a-4f5b-9c8d-7e6f5a4b3c2d"),
+				Doc:        helper.Pointer("0604"),
+			},
+			expected: map[string]interface{}{
+				"ExternalID": helper.Pointer("cus_ABC"),
+				"UUID":       helper.Pointer("0b6f7c1e-2d3a-4f5b-9c8d-7e6f5a4b3c2d"),
+				"Document":   helper.Pointer("0604"),
+				"Name":       "",
+			},
+		},
+		{
+			name:   "Missing required field",
+			data:   &TaggedUpdate{EstabID: helper.Pointer("x")},
+			fields: []string{"Doc"},
+		},
+		{
+			name: "Not a pointer",
+			data: TaggedUpdate{},
+			err:  ErrNotStruct,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			updates, err := ToUpdateE(test.data, nil)
+
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				assert.Nil(t, updates)
+				return
+			}
+
+			if test.fields != nil {
+				var fields FieldErrors
+				require.ErrorAs(t, err, &fields)
+				names := make([]string, len(fields))
+				for i, field := range fields {
+					names[i] = field.Field
+				}
+				assert.Equal(t, test.fields, names)
+				assert.ErrorIs(t, err, ErrRequired)
+				assert.Nil(t, updates)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Contains(t, updates, "UpdatedAt")
+			delete(updates, "UpdatedAt")
+			assert.Equal(t, test.expected, updates)
+		})
+	}
+}
+
+type ProfileUpdate struct {
+	UserID *string
+	Price  *string
+	Born   *string `track:"Birth"`
+	Level  *GQLLevel
+	Note   *string
+	Extra  *string
+}
+
+type ProfileEntity struct {
+	UserID int64
+	Price  Cents
+	Birth  *time.Time
+	Level  Level
+	Note   sql.NullString
+}
+
+// TestToUpdateFor tests the updates converted to the field types of an entity.
+func TestToUpdateFor(t *testing.T) {
+	Register(parseCents)
+	RegisterEnum(LevelLow, LevelHigh)
+
+	born := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string                 // Test case name
+		data     ProfileUpdate          // Update struct
+		expected map[string]interface{} // Expected updates, without UpdatedAt
+		fields   []string               // Fields expected in the FieldErrors
+	}{
+		{
+			name: "Converted fields",
+			data: ProfileUpdate{
+				UserID: helper.Pointer("7"),
+				Price:  helper.Pointer("12.34"),
+				Born:   helper.Pointer("2025-01-02"),
+				Level:  helper.Pointer(GQLLevelLow),
+				Note:   helper.Pointer("hi"),
+				Extra:  helper.Pointer("ignored"),
+			},
+			expected: map[string]interface{}{
+				"UserID": int64(7),
+				"Price":  Cents(1234),
+				"Birth":  &born,
+				"Level":  LevelLow,
+				"Note":   sql.NullString{String: "hi", Valid: true},
+			},
+		},
+		{
+			name:     "Empty ID and nil fields",
+			data:     ProfileUpdate{UserID: helper.Pointer(""), Note: helper.Pointer("")},
+			expected: map[string]interface{}{"Note": sql.NullString{Valid: true}},
+		},
+		{
+			name:   "Invalid values",
+			data:   ProfileUpdate{UserID: helper.Pointer("x"), Level: helper.Pointer(GQLLevelNone)},
+			fields: []string{"UserID", "Level"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			updates, err := ToUpdateFor[ProfileEntity](&test.data, nil)
+
+			if test.fields != nil {
+				var fields FieldErrors
+				require.ErrorAs(t, err, &fields)
+				names := make([]string, len(fields))
+				for i, field := range fields {
+					names[i] = field.Field
+				}
+				assert.Equal(t, test.fields, names)
+				assert.Nil(t, updates)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Contains(t, updates, "UpdatedAt")
+			delete(updates, "UpdatedAt")
+			assert.Equal(t, test.expected, updates)
+		})
+	}
+
+	_, err := ToUpdateFor[int](&ProfileUpdate{}, nil)
+	assert.ErrorIs(t, err, ErrNotStruct)
+}
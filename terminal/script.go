@@ -0,0 +1,9 @@
+package terminal
+
+import "log"
+
+// Script logs a SQL script or any other code fragment in cyan text,
+// keeping it visually apart from the labeled alert messages.
+func Script(code string) {
+	log.Printf("%s%s%s", FgCyan, code, Reset)
+}
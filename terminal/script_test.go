@@ -0,0 +1,20 @@
+package terminal_test
+
+import (
+	"testing"
+
+	"github.com/pinzlab/goutil/terminal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScript(t *testing.T) {
+	code := "CREATE TABLE users (id bigserial PRIMARY KEY);"
+
+	output := captureOutput(func() {
+		terminal.Script(code)
+	})
+
+	assert.Contains(t, output, code, "Output should include the script")
+	assert.Contains(t, output, terminal.FgCyan, "Output should use cyan text")
+	assert.Contains(t, output, terminal.Reset, "Output should reset formatting")
+}